@@ -4,3 +4,8 @@ import "embed"
 
 //go:embed *.json
 var Files embed.FS
+
+// Schema is the JSON Schema that every ACS document must satisfy.
+//
+//go:embed schema/acs.json
+var Schema []byte
@@ -0,0 +1,8 @@
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI document describing the JSON API.
+//
+//go:embed openapi.json
+var OpenAPI []byte
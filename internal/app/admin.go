@@ -0,0 +1,451 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+type adminForm struct {
+	// Errors explains why the last change was not saved.
+	Errors []string
+}
+
+func (a *App) adminHome(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	documents, err := a.acsModel.ListACS(r.Context(), user.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to list ACS documents.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.ACSOptions = documents
+
+	a.render(w, r, http.StatusOK, "admin.html.tmpl", data)
+}
+
+// acsEditor shows the editor for an ACS, or for one of its areas or tasks if they are part of the
+// path.
+func (a *App) acsEditor(w http.ResponseWriter, r *http.Request) {
+	a.renderACSEditor(w, r, http.StatusOK, adminForm{})
+}
+
+// renderACSEditor renders the editor for the most specific part of the ACS in the request path.
+func (a *App) renderACSEditor(w http.ResponseWriter, r *http.Request, status int, form adminForm) {
+	acsID := r.PathValue("acs")
+	areaID := r.PathValue("areaID")
+	taskID := r.PathValue("taskID")
+
+	doc, err := a.acsModel.ExportACS(r.Context(), acsID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to export ACS.", "error", err, "acs", acsID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.Form = form
+	data.ACSDocument = doc
+
+	page := "admin-acs.html.tmpl"
+
+	if areaID == "" {
+		editions, err := a.acsModel.ListEditions(r.Context(), acsID)
+		if err != nil {
+			a.logger.ErrorContext(r.Context(), "Failed to list ACS editions.", "error", err, "acs", acsID)
+			a.serverError(w, r, err)
+			return
+		}
+
+		data.Editions = editions
+	}
+
+	if areaID != "" {
+		area, err := data.ACSDocument.Area(areaID)
+		if err != nil {
+			a.notFound(w, r)
+			return
+		}
+
+		data.DocumentArea = area
+		page = "admin-area.html.tmpl"
+	}
+
+	if taskID != "" {
+		task, err := data.ACSDocument.Task(areaID, taskID)
+		if err != nil {
+			a.notFound(w, r)
+			return
+		}
+
+		data.DocumentTask = task
+		page = "admin-task.html.tmpl"
+	}
+
+	a.render(w, r, status, page, data)
+}
+
+// adminExportACS downloads the stored copy of an ACS in the same format used by populate-acs.
+func (a *App) adminExportACS(w http.ResponseWriter, r *http.Request) {
+	acsID := r.PathValue("acs")
+
+	doc, err := a.acsModel.ExportACS(r.Context(), acsID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to export ACS.", "error", err, "acs", acsID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	if err := models.ValidateACS(doc); err != nil {
+		a.logger.ErrorContext(r.Context(), "Exported ACS is not valid.", "error", err, "acs", acsID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	body := new(bytes.Buffer)
+	if err := models.EncodeACS(body, doc); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to encode ACS.", "error", err, "acs", acsID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	filename := strings.ToLower(doc.EditionID()) + ".json"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	body.WriteTo(w)
+}
+
+// saveACSEdit applies an edit to the ACS in the request path and redirects to next. If the edit is
+// rejected, the editor is shown again with the reasons.
+func (a *App) saveACSEdit(w http.ResponseWriter, r *http.Request, edit models.ACSEdit, next string) {
+	acsID := r.PathValue("acs")
+	allowDataLoss := r.PostForm.Get("allowDataLoss") != ""
+
+	_, err := a.acsModel.EditACS(r.Context(), acsID, edit, allowDataLoss)
+	if err != nil {
+		var validationErr *models.ACSValidationError
+		var dataLossErr *models.DataLossError
+
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			a.notFound(w, r)
+
+		case errors.As(err, &validationErr):
+			form := adminForm{}
+			for _, v := range validationErr.Violations {
+				form.Errors = append(form.Errors, v.String())
+			}
+
+			a.renderACSEditor(w, r, http.StatusUnprocessableEntity, form)
+
+		case errors.As(err, &dataLossErr):
+			form := adminForm{}
+			for _, e := range dataLossErr.Elements {
+				form.Errors = append(form.Errors, fmt.Sprintf(
+					"%s has %d votes, %d history events, %d notes, %d sign-offs, %d missed knowledge test answers, and %d exam grades.",
+					e.FullPublicID,
+					e.Votes,
+					e.HistoryEvents,
+					e.Notes,
+					e.SignOffs,
+					e.Missed,
+					e.ExamGrades,
+				))
+			}
+
+			form.Errors = append(form.Errors, "Check \"Remove user data\" to delete it anyway.")
+
+			a.renderACSEditor(w, r, http.StatusConflict, form)
+
+		default:
+			a.logger.ErrorContext(r.Context(), "Failed to edit ACS.", "error", err, "acs", acsID)
+			a.serverError(w, r, err)
+		}
+
+		return
+	}
+
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// parseAdminForm parses the submitted form, rendering an error if it is malformed.
+func (a *App) parseAdminForm(w http.ResponseWriter, r *http.Request) bool {
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// moveOffset converts the direction of a move button into an offset.
+func moveOffset(r *http.Request) int {
+	if r.PostForm.Get("direction") == "up" {
+		return -1
+	}
+
+	return 1
+}
+
+// formLines splits a text area into its non-empty lines.
+func formLines(value string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// formSubElements reads sub-elements from a text area with one per line.
+func formSubElements(value string) []models.ExternalSubElement {
+	var subElements []models.ExternalSubElement
+	for _, line := range formLines(value) {
+		subElements = append(subElements, models.ExternalSubElement{Content: line})
+	}
+
+	return subElements
+}
+
+type adminElementGroup struct {
+	Type     models.TaskElementType
+	Title    string
+	Elements []models.ExternalElement
+}
+
+// adminElementGroups lists a task's elements by type, in the order they appear in the ACS. PTS
+// tasks have a single list of elements.
+func adminElementGroups(acs models.ExternalACS, task *models.ExternalTask) []adminElementGroup {
+	if acs.DocumentKind() == models.ACSKindPTS {
+		return []adminElementGroup{{models.TaskElementTypePTS, "Elements", task.PTSElements}}
+	}
+
+	return []adminElementGroup{
+		{models.TaskElementTypeKnowledge, "Knowledge", task.Knowledge},
+		{models.TaskElementTypeRiskManagement, "Risk Management", task.RiskManagement},
+		{models.TaskElementTypeSkills, "Skills", task.Skills},
+	}
+}
+
+func isElementType(t models.TaskElementType) bool {
+	switch t {
+	case models.TaskElementTypeKnowledge, models.TaskElementTypeRiskManagement, models.TaskElementTypeSkills,
+		models.TaskElementTypePTS:
+		return true
+	}
+
+	return false
+}
+
+// parseElementPublicID splits an element ID like "K3" into its type and number.
+func parseElementPublicID(id string) (models.TaskElementType, int32, bool) {
+	if id == "" {
+		return "", 0, false
+	}
+
+	elementType := models.TaskElementType(id[:1])
+	if !isElementType(elementType) {
+		return "", 0, false
+	}
+
+	number, err := strconv.ParseInt(id[1:], 10, 32)
+	if err != nil || number < 1 {
+		return "", 0, false
+	}
+
+	return elementType, int32(number), true
+}
+
+func acsEditorURL(r *http.Request) string {
+	return "/admin/acs/" + r.PathValue("acs")
+}
+
+func areaEditorURL(r *http.Request) string {
+	return acsEditorURL(r) + "/" + r.PathValue("areaID")
+}
+
+func taskEditorURL(r *http.Request) string {
+	return areaEditorURL(r) + "/" + r.PathValue("taskID")
+}
+
+func (a *App) adminUpdateACS(w http.ResponseWriter, r *http.Request) {
+	if !a.parseAdminForm(w, r) {
+		return
+	}
+
+	name := strings.TrimSpace(r.PostForm.Get("name"))
+
+	a.saveACSEdit(w, r, models.SetACSName(name), acsEditorURL(r))
+}
+
+func (a *App) adminAddArea(w http.ResponseWriter, r *http.Request) {
+	if !a.parseAdminForm(w, r) {
+		return
+	}
+
+	id := strings.ToUpper(strings.TrimSpace(r.PostForm.Get("id")))
+	name := strings.TrimSpace(r.PostForm.Get("name"))
+
+	a.saveACSEdit(w, r, models.AddArea(id, name), acsEditorURL(r)+"/"+id)
+}
+
+func (a *App) adminUpdateArea(w http.ResponseWriter, r *http.Request) {
+	if !a.parseAdminForm(w, r) {
+		return
+	}
+
+	name := strings.TrimSpace(r.PostForm.Get("name"))
+
+	a.saveACSEdit(w, r, models.RenameArea(r.PathValue("areaID"), name), areaEditorURL(r))
+}
+
+func (a *App) adminMoveArea(w http.ResponseWriter, r *http.Request) {
+	if !a.parseAdminForm(w, r) {
+		return
+	}
+
+	a.saveACSEdit(w, r, models.MoveArea(r.PathValue("areaID"), moveOffset(r)), acsEditorURL(r))
+}
+
+func (a *App) adminRemoveArea(w http.ResponseWriter, r *http.Request) {
+	if !a.parseAdminForm(w, r) {
+		return
+	}
+
+	a.saveACSEdit(w, r, models.RemoveArea(r.PathValue("areaID")), acsEditorURL(r))
+}
+
+func (a *App) adminAddTask(w http.ResponseWriter, r *http.Request) {
+	if !a.parseAdminForm(w, r) {
+		return
+	}
+
+	task := models.ExternalTask{
+		ID:        strings.ToUpper(strings.TrimSpace(r.PostForm.Get("id"))),
+		Name:      strings.TrimSpace(r.PostForm.Get("name")),
+		Objective: strings.TrimSpace(r.PostForm.Get("objective")),
+	}
+
+	a.saveACSEdit(w, r, models.AddTask(r.PathValue("areaID"), task), areaEditorURL(r)+"/"+task.ID)
+}
+
+func (a *App) adminUpdateTask(w http.ResponseWriter, r *http.Request) {
+	if !a.parseAdminForm(w, r) {
+		return
+	}
+
+	update := models.ExternalTask{
+		Name:       strings.TrimSpace(r.PostForm.Get("name")),
+		Objective:  strings.TrimSpace(r.PostForm.Get("objective")),
+		Note:       strings.TrimSpace(r.PostForm.Get("note")),
+		AppliesTo:  r.PostForm["appliesTo"],
+		References: formLines(r.PostForm.Get("references")),
+	}
+
+	edit := models.UpdateTask(r.PathValue("areaID"), r.PathValue("taskID"), update)
+	a.saveACSEdit(w, r, edit, taskEditorURL(r))
+}
+
+func (a *App) adminMoveTask(w http.ResponseWriter, r *http.Request) {
+	if !a.parseAdminForm(w, r) {
+		return
+	}
+
+	edit := models.MoveTask(r.PathValue("areaID"), r.PathValue("taskID"), moveOffset(r))
+	a.saveACSEdit(w, r, edit, areaEditorURL(r))
+}
+
+func (a *App) adminRemoveTask(w http.ResponseWriter, r *http.Request) {
+	if !a.parseAdminForm(w, r) {
+		return
+	}
+
+	a.saveACSEdit(w, r, models.RemoveTask(r.PathValue("areaID"), r.PathValue("taskID")), areaEditorURL(r))
+}
+
+func (a *App) adminAddElement(w http.ResponseWriter, r *http.Request) {
+	if !a.parseAdminForm(w, r) {
+		return
+	}
+
+	elementType := models.TaskElementType(r.PostForm.Get("type"))
+	if !isElementType(elementType) {
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	element := models.ExternalElement{
+		Content:     strings.TrimSpace(r.PostForm.Get("content")),
+		SubElements: formSubElements(r.PostForm.Get("subElements")),
+	}
+
+	edit := models.AddElement(r.PathValue("areaID"), r.PathValue("taskID"), elementType, element)
+	a.saveACSEdit(w, r, edit, taskEditorURL(r))
+}
+
+// elementFromPath parses the element in the request path, rendering a 404 if it isn't valid.
+func (a *App) elementFromPath(w http.ResponseWriter, r *http.Request) (models.TaskElementType, int32, bool) {
+	elementType, id, ok := parseElementPublicID(r.PathValue("elementID"))
+	if !ok {
+		a.notFound(w, r)
+	}
+
+	return elementType, id, ok
+}
+
+func (a *App) adminUpdateElement(w http.ResponseWriter, r *http.Request) {
+	elementType, id, ok := a.elementFromPath(w, r)
+	if !ok || !a.parseAdminForm(w, r) {
+		return
+	}
+
+	update := models.ExternalElement{
+		Content:     strings.TrimSpace(r.PostForm.Get("content")),
+		SubElements: formSubElements(r.PostForm.Get("subElements")),
+	}
+
+	edit := models.UpdateElement(r.PathValue("areaID"), r.PathValue("taskID"), elementType, id, update)
+	a.saveACSEdit(w, r, edit, taskEditorURL(r))
+}
+
+func (a *App) adminMoveElement(w http.ResponseWriter, r *http.Request) {
+	elementType, id, ok := a.elementFromPath(w, r)
+	if !ok || !a.parseAdminForm(w, r) {
+		return
+	}
+
+	edit := models.MoveElement(r.PathValue("areaID"), r.PathValue("taskID"), elementType, id, moveOffset(r))
+	a.saveACSEdit(w, r, edit, taskEditorURL(r))
+}
+
+func (a *App) adminRemoveElement(w http.ResponseWriter, r *http.Request) {
+	elementType, id, ok := a.elementFromPath(w, r)
+	if !ok || !a.parseAdminForm(w, r) {
+		return
+	}
+
+	edit := models.RemoveElement(r.PathValue("areaID"), r.PathValue("taskID"), elementType, id)
+	a.saveACSEdit(w, r, edit, taskEditorURL(r))
+}
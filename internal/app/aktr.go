@@ -0,0 +1,100 @@
+package app
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+// maxReportBytes limits the size of uploaded knowledge test reports. Reports are only a couple of
+// pages, so this is generous.
+const maxReportBytes = 1 << 20
+
+type knowledgeTestForm struct {
+	Codes         string
+	LowConfidence bool
+
+	FieldErrors map[string]string
+
+	// Result is set once a report has been imported.
+	Result *models.KnowledgeTestImport
+}
+
+func (a *App) knowledgeTestForm(w http.ResponseWriter, r *http.Request) {
+	data := a.newTemplateData(r)
+	data.Form = knowledgeTestForm{}
+
+	a.render(w, r, http.StatusOK, "knowledge-test.html.tmpl", data)
+}
+
+func (a *App) importKnowledgeTest(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxReportBytes)
+	if err := r.ParseMultipartForm(maxReportBytes); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form := knowledgeTestForm{
+		Codes:         r.PostForm.Get("codes"),
+		LowConfidence: r.PostForm.Has("low_confidence"),
+		FieldErrors:   make(map[string]string),
+	}
+
+	// Pasted codes and an uploaded report are combined, so either or both may be given.
+	report := form.Codes
+	file, _, err := r.FormFile("report")
+	if err == nil {
+		defer file.Close()
+
+		contents, err := io.ReadAll(file)
+		if err != nil {
+			a.logger.ErrorContext(r.Context(), "Failed to read uploaded report.", "error", err)
+			a.genericError(w, r, http.StatusBadRequest)
+			return
+		}
+
+		report += "\n" + string(contents)
+	} else if !errors.Is(err, http.ErrMissingFile) {
+		a.logger.ErrorContext(r.Context(), "Failed to open uploaded report.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	codes := models.ParseACSCodes(report)
+	if len(codes) == 0 {
+		if strings.TrimSpace(report) == "" {
+			form.FieldErrors["codes"] = "Paste the codes from your report or upload it."
+		} else {
+			form.FieldErrors["codes"] = "No ACS codes like PA.I.B.K3 were found."
+		}
+
+		data := a.newTemplateData(r)
+		data.Form = form
+		a.render(w, r, http.StatusUnprocessableEntity, "knowledge-test.html.tmpl", data)
+		return
+	}
+
+	result, err := a.acsModel.ImportKnowledgeTest(
+		r.Context(),
+		user.ID,
+		codes,
+		models.KnowledgeTestImportOptions{SetLowConfidence: form.LowConfidence},
+	)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to import knowledge test report.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	form.Result = &result
+
+	data := a.newTemplateData(r)
+	data.Form = form
+	a.render(w, r, http.StatusOK, "knowledge-test.html.tmpl", data)
+}
@@ -0,0 +1,620 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cdriehuys/flight-school/api"
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+// maxAPIRequestBytes limits the size of JSON request bodies.
+const maxAPIRequestBytes = 1 << 20
+
+type apiErrorResponse struct {
+	Status  int    `json:"status"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+type apiConfidence struct {
+	Votes    int `json:"votes"`
+	Possible int `json:"possible"`
+}
+
+func apiConfidenceFromModel(c models.Confidence) apiConfidence {
+	return apiConfidence{Votes: c.Votes, Possible: c.Possible}
+}
+
+type apiACS struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Kind       string        `json:"kind"`
+	AreaCount  int           `json:"areaCount"`
+	Confidence apiConfidence `json:"confidence"`
+	Studying   bool          `json:"studying"`
+}
+
+func apiACSFromModel(acs models.ACS) apiACS {
+	return apiACS{
+		ID:         acs.ID,
+		Name:       acs.Name,
+		Kind:       string(acs.Kind),
+		AreaCount:  acs.AreaCount,
+		Confidence: apiConfidenceFromModel(acs.Confidence),
+		Studying:   acs.Studying,
+	}
+}
+
+type apiACSDetail struct {
+	apiACS
+	Areas []apiArea `json:"areas"`
+}
+
+type apiArea struct {
+	ID           string        `json:"id"`
+	FullPublicID string        `json:"fullPublicId"`
+	Name         string        `json:"name"`
+	TaskCount    int           `json:"taskCount"`
+	Confidence   apiConfidence `json:"confidence"`
+}
+
+func apiAreaFromModel(area models.AreaOfOperation) apiArea {
+	return apiArea{
+		ID:           area.PublicID,
+		FullPublicID: area.FullID(),
+		Name:         area.Name,
+		TaskCount:    area.TaskCount,
+		Confidence:   apiConfidenceFromModel(area.Confidence),
+	}
+}
+
+type apiAreaDetail struct {
+	apiArea
+	Tasks []apiTaskSummary `json:"tasks"`
+}
+
+type apiElementCounts struct {
+	Knowledge      int `json:"knowledge"`
+	RiskManagement int `json:"riskManagement"`
+	Skills         int `json:"skills"`
+	Elements       int `json:"elements"`
+}
+
+type apiTaskSummary struct {
+	ID            string           `json:"id"`
+	FullPublicID  string           `json:"fullPublicId"`
+	Name          string           `json:"name"`
+	Objective     string           `json:"objective"`
+	Confidence    apiConfidence    `json:"confidence"`
+	ElementCounts apiElementCounts `json:"elementCounts"`
+}
+
+func apiTaskSummaryFromModel(task models.TaskSummary) apiTaskSummary {
+	return apiTaskSummary{
+		ID:           task.PublicID,
+		FullPublicID: task.FullPublicID,
+		Name:         task.Name,
+		Objective:    task.Objective,
+		Confidence:   apiConfidenceFromModel(task.Confidence),
+		ElementCounts: apiElementCounts{
+			Knowledge:      task.KnowledgeElementCount,
+			RiskManagement: task.RiskManagementElementCount,
+			Skills:         task.SkillElementCount,
+			Elements:       task.PTSElementCount,
+		},
+	}
+}
+
+type apiTask struct {
+	ID             string        `json:"id"`
+	FullPublicID   string        `json:"fullPublicId"`
+	Name           string        `json:"name"`
+	Objective      string        `json:"objective"`
+	Note           string        `json:"note"`
+	Confidence     apiConfidence `json:"confidence"`
+	References     []string      `json:"references"`
+	Knowledge      []apiElement  `json:"knowledge"`
+	RiskManagement []apiElement  `json:"riskManagement"`
+	Skills         []apiElement  `json:"skills"`
+	Elements       []apiElement  `json:"elements"`
+}
+
+func apiTaskFromModel(task models.Task) apiTask {
+	references := task.References
+	if references == nil {
+		references = []string{}
+	}
+
+	return apiTask{
+		ID:             task.PublicID,
+		FullPublicID:   task.FullPublicID(),
+		Name:           task.Name,
+		Objective:      task.Objective,
+		Note:           task.Note,
+		Confidence:     apiConfidenceFromModel(task.Confidence),
+		References:     references,
+		Knowledge:      apiElementsFromModel(task.KnowledgeElements),
+		RiskManagement: apiElementsFromModel(task.RiskManagementElements),
+		Skills:         apiElementsFromModel(task.SkillElements),
+		Elements:       apiElementsFromModel(task.PTSElements),
+	}
+}
+
+type apiElement struct {
+	// ID is the stable identifier used to address the element in other requests.
+	ID              int32                  `json:"id"`
+	PublicID        int32                  `json:"publicId"`
+	Type            models.TaskElementType `json:"type"`
+	FullPublicID    string                 `json:"fullPublicId"`
+	Content         string                 `json:"content"`
+	Confidence      *string                `json:"confidence"`
+	ReviewDueAt     *time.Time             `json:"reviewDueAt"`
+	MissedOnWritten bool                   `json:"missedOnWritten"`
+	SubElements     []apiSubElement        `json:"subElements"`
+}
+
+type apiSubElement struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
+}
+
+func apiElementFromModel(element models.TaskElement) apiElement {
+	subElements := make([]apiSubElement, len(element.SubElements))
+	for i, s := range element.SubElements {
+		subElements[i] = apiSubElement{ID: s.PublicID(), Content: s.Content}
+	}
+
+	e := apiElement{
+		ID:              element.ID,
+		PublicID:        element.PublicID,
+		Type:            element.Type,
+		FullPublicID:    element.FullPublicID,
+		Content:         element.Content,
+		ReviewDueAt:     element.ReviewDueAt,
+		MissedOnWritten: element.MissedOnWritten,
+		SubElements:     subElements,
+	}
+
+	if element.ConfidenceLevel != nil {
+		level := apiConfidenceLevelNames[*element.ConfidenceLevel]
+		e.Confidence = &level
+	}
+
+	return e
+}
+
+func apiElementsFromModel(elements []models.TaskElement) []apiElement {
+	converted := make([]apiElement, len(elements))
+	for i, e := range elements {
+		converted[i] = apiElementFromModel(e)
+	}
+
+	return converted
+}
+
+var apiConfidenceLevelNames = map[models.ConfidenceLevel]string{
+	models.ConfidenceLevelLow:    "low",
+	models.ConfidenceLevelMedium: "medium",
+	models.ConfidenceLevelHigh:   "high",
+}
+
+type apiSearchResult struct {
+	Task    apiSearchTask    `json:"task"`
+	Matches []apiSearchMatch `json:"matches"`
+}
+
+type apiSearchTask struct {
+	ACS          string `json:"acs"`
+	AreaID       string `json:"areaId"`
+	ID           string `json:"id"`
+	FullPublicID string `json:"fullPublicId"`
+	Name         string `json:"name"`
+}
+
+type apiSearchMatch struct {
+	Kind     models.SearchMatchKind `json:"kind"`
+	Location *string                `json:"location"`
+	Snippet  []apiSnippetPart       `json:"snippet"`
+}
+
+type apiSnippetPart struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match"`
+}
+
+func apiSearchResultFromModel(result models.SearchResult) apiSearchResult {
+	converted := apiSearchResult{
+		Task: apiSearchTask{
+			ACS:          result.Task.Area.ACS,
+			AreaID:       result.Task.Area.PublicID,
+			ID:           result.Task.PublicID,
+			FullPublicID: result.Task.FullPublicID(),
+			Name:         result.Task.Name,
+		},
+		Matches: make([]apiSearchMatch, len(result.Matches)),
+	}
+
+	for i, m := range result.Matches {
+		match := apiSearchMatch{Kind: m.Kind, Snippet: make([]apiSnippetPart, len(m.Snippet))}
+		if location := m.Location(); location != "" {
+			match.Location = &location
+		}
+
+		for j, part := range m.Snippet {
+			match.Snippet[j] = apiSnippetPart{Text: part.Text, Match: part.Match}
+		}
+
+		converted.Matches[i] = match
+	}
+
+	return converted
+}
+
+type apiProgressImport struct {
+	Matched int      `json:"matched"`
+	Unknown []string `json:"unknown"`
+	Votes   int64    `json:"votes"`
+	Reviews int64    `json:"reviews"`
+	Missed  int64    `json:"missed"`
+	Notes   int64    `json:"notes"`
+}
+
+type apiConfidenceRequest struct {
+	Level string `json:"level"`
+}
+
+func (a *App) apiListACS(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	documents, err := a.acsModel.ListACS(r.Context(), user.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to list ACS documents.", "error", err)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	response := make([]apiACS, len(documents))
+	for i, acs := range documents {
+		response[i] = apiACSFromModel(acs)
+	}
+
+	a.writeJSON(w, r, http.StatusOK, response)
+}
+
+func (a *App) apiGetACS(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	acsID := r.PathValue("acs")
+
+	acs, err := a.acsModel.GetACS(r.Context(), user.ID, acsID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.apiError(w, r, http.StatusNotFound, fmt.Sprintf("There is no ACS with the ID %q.", acsID))
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve ACS.", "error", err, "acs", acsID)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	areas, err := a.acsModel.ListAreasByACS(r.Context(), user.ID, acs.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to list ACS areas.", "error", err, "acs", acs.ID)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	response := apiACSDetail{apiACS: apiACSFromModel(acs), Areas: make([]apiArea, len(areas))}
+	for i, area := range areas {
+		response.Areas[i] = apiAreaFromModel(area)
+	}
+
+	a.writeJSON(w, r, http.StatusOK, response)
+}
+
+func (a *App) apiGetArea(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	acs := r.PathValue("acs")
+	areaID := r.PathValue("areaID")
+
+	area, err := a.acsModel.GetAreaByID(r.Context(), acs, areaID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.apiError(w, r, http.StatusNotFound, fmt.Sprintf("There is no area with the ID %q.", acs+"."+areaID))
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve ACS area.", "error", err, "acs", acs, "area", areaID)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	tasks, err := a.acsModel.ListTasksByArea(r.Context(), user.ID, area.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to list tasks for area.", "error", err, "acs", acs, "area", areaID)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	// The area lookup doesn't include summary information, but it's the sum of the task summaries.
+	area.TaskCount = len(tasks)
+
+	response := apiAreaDetail{Tasks: make([]apiTaskSummary, len(tasks))}
+	for i, task := range tasks {
+		area.Confidence.Votes += task.Confidence.Votes
+		area.Confidence.Possible += task.Confidence.Possible
+
+		response.Tasks[i] = apiTaskSummaryFromModel(task)
+	}
+
+	response.apiArea = apiAreaFromModel(area)
+
+	a.writeJSON(w, r, http.StatusOK, response)
+}
+
+func (a *App) apiGetTask(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	acs := r.PathValue("acs")
+	areaID := r.PathValue("areaID")
+	taskID := r.PathValue("taskID")
+
+	task, err := a.acsModel.GetTaskByArea(r.Context(), user.ID, acs, areaID, taskID)
+	if err != nil {
+		fullID := fmt.Sprintf("%s.%s.%s", acs, areaID, taskID)
+		if errors.Is(err, models.ErrNotFound) {
+			a.apiError(w, r, http.StatusNotFound, fmt.Sprintf("There is no task with the ID %q.", fullID))
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve task.", "error", err, "taskPublicID", fullID)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, apiTaskFromModel(task))
+}
+
+func (a *App) apiGetElement(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	elementID, ok := a.apiElementID(w, r)
+	if !ok {
+		return
+	}
+
+	element, err := a.acsModel.GetElement(r.Context(), user.ID, elementID)
+	if err != nil {
+		a.apiElementError(w, r, elementID, err)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, apiElementFromModel(element))
+}
+
+func (a *App) apiSetElementConfidence(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	elementID, ok := a.apiElementID(w, r)
+	if !ok {
+		return
+	}
+
+	var request apiConfidenceRequest
+	if !a.readJSON(w, r, &request) {
+		return
+	}
+
+	var level models.ConfidenceLevel
+	for l, name := range apiConfidenceLevelNames {
+		if name == request.Level {
+			level = l
+		}
+	}
+
+	if level == 0 {
+		a.apiError(w, r, http.StatusBadRequest, `The confidence level must be one of "high", "medium", or "low".`)
+		return
+	}
+
+	// Check the element exists first so that a missing element is reported as such rather than as a
+	// failure to save the vote.
+	if _, err := a.acsModel.GetElement(r.Context(), user.ID, elementID); err != nil {
+		a.apiElementError(w, r, elementID, err)
+		return
+	}
+
+	if err := a.acsModel.SetElementConfidence(r.Context(), user.ID, elementID, level); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to set element confidence.", "error", err, "elementID", elementID, "confidence", level)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	element, err := a.acsModel.GetElement(r.Context(), user.ID, elementID)
+	if err != nil {
+		a.apiElementError(w, r, elementID, err)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, apiElementFromModel(element))
+}
+
+func (a *App) apiClearElementConfidence(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	elementID, ok := a.apiElementID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := a.acsModel.GetElement(r.Context(), user.ID, elementID); err != nil {
+		a.apiElementError(w, r, elementID, err)
+		return
+	}
+
+	if err := a.acsModel.ClearElementConfidence(r.Context(), user.ID, elementID); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to clear element confidence.", "error", err, "elementID", elementID)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *App) apiSearch(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	form, ok := parseSearchForm(r)
+	if !ok {
+		message := "The q parameter is required."
+		if err, found := form.FieldErrors["q"]; found {
+			message = err
+		}
+
+		a.apiError(w, r, http.StatusBadRequest, message)
+		return
+	}
+
+	results, err := a.acsModel.Search(r.Context(), user.ID, form.Query, form.ACS)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to search.", "error", err, "query", form.Query)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	response := make([]apiSearchResult, len(results))
+	for i, result := range results {
+		response[i] = apiSearchResultFromModel(result)
+	}
+
+	a.writeJSON(w, r, http.StatusOK, response)
+}
+
+func (a *App) apiExportProgress(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	progress, err := a.acsModel.ExportProgress(r.Context(), user.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to export progress.", "error", err)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, progress)
+}
+
+func (a *App) apiImportProgress(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	// Progress files are larger than other request bodies, so they aren't read with readJSON.
+	r.Body = http.MaxBytesReader(w, r.Body, maxProgressBytes)
+
+	var progress models.Progress
+	if err := json.NewDecoder(r.Body).Decode(&progress); err != nil {
+		a.apiError(w, r, http.StatusBadRequest, fmt.Sprintf("The request body is not valid: %v", err))
+		return
+	}
+
+	if err := models.ValidateProgress(progress); err != nil {
+		a.apiError(w, r, http.StatusUnprocessableEntity, fmt.Sprintf("The progress can't be imported: %v", err))
+		return
+	}
+
+	result, err := a.acsModel.ImportProgress(r.Context(), user.ID, progress)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to import progress.", "error", err)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, apiProgressImport{
+		Matched: result.Matched,
+		Unknown: result.Unknown,
+		Votes:   result.Votes,
+		Reviews: result.Reviews,
+		Missed:  result.Missed,
+		Notes:   result.Notes,
+	})
+}
+
+func (a *App) apiOpenAPIDocument(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(api.OpenAPI)
+}
+
+func (a *App) apiNotFound(w http.ResponseWriter, r *http.Request) {
+	a.apiError(w, r, http.StatusNotFound, "There is no API endpoint at this path.")
+}
+
+// apiElementID parses the element ID from the request path. If the ID is invalid, an error
+// response is written and false is returned.
+func (a *App) apiElementID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	elementID, err := strconv.ParseInt(r.PathValue("elementID"), 10, 32)
+	if err != nil {
+		a.apiError(w, r, http.StatusBadRequest, "Element IDs must be integers.")
+		return 0, false
+	}
+
+	return int32(elementID), true
+}
+
+func (a *App) apiElementError(w http.ResponseWriter, r *http.Request, elementID int32, err error) {
+	if errors.Is(err, models.ErrNotFound) {
+		a.apiError(w, r, http.StatusNotFound, fmt.Sprintf("There is no element with the ID %d.", elementID))
+		return
+	}
+
+	a.logger.ErrorContext(r.Context(), "Failed to retrieve element.", "error", err, "elementID", elementID)
+	a.apiServerError(w, r, err)
+}
+
+// readJSON decodes a JSON request body. If the body is invalid, an error response is written and
+// false is returned.
+func (a *App) readJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAPIRequestBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		a.apiError(w, r, http.StatusBadRequest, fmt.Sprintf("The request body is not valid: %v", err))
+		return false
+	}
+
+	return true
+}
+
+func (a *App) writeJSON(w http.ResponseWriter, r *http.Request, status int, data any) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to encode JSON response.", "error", err)
+		a.apiServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// apiError writes a JSON error response with a message describing what went wrong.
+func (a *App) apiError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	a.writeJSON(w, r, status, apiErrorResponse{
+		Status:  status,
+		Error:   http.StatusText(status),
+		Message: message,
+	})
+}
+
+func (a *App) apiServerError(w http.ResponseWriter, r *http.Request, err error) {
+	message := "The server encountered an unexpected error."
+	if a.debug {
+		message = err.Error()
+	}
+
+	a.apiError(w, r, http.StatusInternalServerError, message)
+}
@@ -6,7 +6,10 @@ import (
 	"html/template"
 	"io/fs"
 	"log/slog"
+	"time"
 
+	"github.com/alexedwards/scs/pgxstore"
+	"github.com/alexedwards/scs/v2"
 	"github.com/cdriehuys/flight-school/internal/models"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -15,8 +18,10 @@ type App struct {
 	logger      *slog.Logger
 	templates   templateEngine
 	staticfiles staticfiles
+	sessions    *scs.SessionManager
 
-	acsModel acsModel
+	acsModel  acsModel
+	userModel userModel
 
 	debug bool
 }
@@ -30,15 +35,58 @@ type Options struct {
 }
 
 type acsModel interface {
+	ListACS(ctx context.Context, userID int32) ([]models.ACS, error)
+	GetACS(ctx context.Context, userID int32, id string) (models.ACS, error)
+	SetStudying(ctx context.Context, userID int32, acsID string, studying bool) error
+	ListEditions(ctx context.Context, acsID string) ([]models.ACS, error)
+	SetCurrentEdition(ctx context.Context, acsID string) error
+	CompareEditions(ctx context.Context, fromID string, toID string) (models.ACSDiff, error)
 	GetAreaByID(ctx context.Context, acs string, areaID string) (models.AreaOfOperation, error)
-	GetTaskByArea(ctx context.Context, acs string, areaID string, taskID string) (models.Task, error)
-	GetTaskByElementID(ctx context.Context, elementID int32) (models.Task, error)
-	GetTaskConfidence(ctx context.Context, taskID int32) (models.Confidence, error)
-	ListAreasByACS(ctx context.Context, acs string) ([]models.AreaOfOperation, error)
-	ListTasksByArea(ctx context.Context, areaID int32) ([]models.TaskSummary, error)
+	GetTaskByArea(ctx context.Context, userID int32, acs string, areaID string, taskID string) (models.Task, error)
+	GetTaskByElementID(ctx context.Context, userID int32, elementID int32) (models.Task, error)
+	GetTaskConfidence(ctx context.Context, userID int32, taskID int32) (models.Confidence, error)
+	ListAreasByACS(ctx context.Context, userID int32, acs string) ([]models.AreaOfOperation, error)
+	ListTasksByArea(ctx context.Context, userID int32, areaID int32) ([]models.TaskSummary, error)
+	ListWeakestTasks(ctx context.Context, userID int32, acsID string, count int) ([]models.TaskSummary, error)
+	SignOffElement(ctx context.Context, instructorID int32, studentID int32, elementID int32) error
+	RemoveElementSignOff(ctx context.Context, studentID int32, elementID int32) error
+	GetElement(ctx context.Context, userID int32, elementID int32) (models.TaskElement, error)
 	GetElementPublicIDByID(ctx context.Context, elementID int32) (string, error)
-	SetElementConfidence(ctx context.Context, elementID int32, confidence models.ConfidenceLevel) error
-	ClearElementConfidence(ctx context.Context, elementID int32) error
+	SetElementConfidence(ctx context.Context, userID int32, elementID int32, confidence models.ConfidenceLevel) error
+	ClearElementConfidence(ctx context.Context, userID int32, elementID int32) error
+	SetElementNote(ctx context.Context, userID int32, elementID int32, content string) error
+	SetSubElementNote(ctx context.Context, userID int32, elementID int32, order int32, content string) error
+	ImportKnowledgeTest(ctx context.Context, userID int32, codes []string, opts models.KnowledgeTestImportOptions) (models.KnowledgeTestImport, error)
+	ListDueReviews(ctx context.Context, userID int32, asOf time.Time) ([]models.ReviewItem, error)
+	GetAreaConfidenceHistory(ctx context.Context, userID int32, areaID int32) ([]models.ConfidenceSnapshot, error)
+	GetTaskConfidenceHistory(ctx context.Context, userID int32, taskID int32) ([]models.ConfidenceSnapshot, error)
+	StartStudySession(ctx context.Context, userID int32, acsID string, areaPublicID string, count int) (int32, error)
+	GetStudySession(ctx context.Context, userID int32, sessionID int32) (models.StudySession, error)
+	RateStudyElement(ctx context.Context, userID int32, sessionID int32, elementID int32, confidence models.ConfidenceLevel) error
+	EndStudySession(ctx context.Context, userID int32, sessionID int32) error
+	StartPracticeTest(ctx context.Context, userID int32, acsID string, areaPublicID string, count int) (int32, error)
+	GetPracticeTest(ctx context.Context, userID int32, testID int32) (models.PracticeTest, error)
+	SubmitPracticeTest(ctx context.Context, userID int32, testID int32, answers map[int32]int32) error
+	CreateExam(ctx context.Context, examinerID int32, studentID int32, opts models.ExamOptions) (int32, error)
+	ListExams(ctx context.Context, userID int32) ([]models.ExamSummary, error)
+	GetExam(ctx context.Context, userID int32, examID int32) (models.Exam, error)
+	GradeExamElement(ctx context.Context, examinerID int32, examID int32, elementID int32, grade models.ExamGrade) error
+	Search(ctx context.Context, userID int32, query string, acs string) ([]models.SearchResult, error)
+	ExportProgress(ctx context.Context, userID int32) (models.Progress, error)
+	ImportProgress(ctx context.Context, userID int32, progress models.Progress) (models.ProgressImport, error)
+	ExportACS(ctx context.Context, acsID string) (models.ExternalACS, error)
+	EditACS(ctx context.Context, acsID string, edit models.ACSEdit, allowDataLoss bool) (models.PopulateReport, error)
+}
+
+type userModel interface {
+	Insert(ctx context.Context, username string, password string) (int32, error)
+	Authenticate(ctx context.Context, username string, password string) (int32, error)
+	GetByID(ctx context.Context, id int32) (models.User, error)
+	GetByAPIToken(ctx context.Context, token string) (models.User, error)
+	ListStudents(ctx context.Context, instructorID int32) ([]models.Student, error)
+	GetStudent(ctx context.Context, instructorID int32, studentID int32) (models.User, error)
+	GetStudentByUsername(ctx context.Context, instructorID int32, username string) (models.User, error)
+	SetClassRating(ctx context.Context, userID int32, rating *models.ClassRating) error
 }
 
 func New(
@@ -68,13 +116,20 @@ func New(
 		}
 	}
 
+	sessions := scs.New()
+	sessions.Store = pgxstore.New(db)
+	sessions.Lifetime = 7 * 24 * time.Hour
+
 	acsModel := models.NewACSModel(logger, db)
+	userModel := models.NewUserModel(logger, db)
 
 	app := &App{
 		logger:      logger,
 		templates:   templates,
 		staticfiles: sf,
+		sessions:    sessions,
 		acsModel:    acsModel,
+		userModel:   userModel,
 		debug:       options.Debug,
 	}
 
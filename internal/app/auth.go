@@ -0,0 +1,164 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+const minPasswordLength = 8
+
+type userForm struct {
+	Username string
+	Next     string
+
+	FieldErrors   map[string]string
+	NonFieldError string
+}
+
+func (f userForm) Valid() bool {
+	return len(f.FieldErrors) == 0 && f.NonFieldError == ""
+}
+
+func (a *App) signupForm(w http.ResponseWriter, r *http.Request) {
+	data := a.newTemplateData(r)
+	data.Form = userForm{}
+
+	a.render(w, r, http.StatusOK, "signup.html.tmpl", data)
+}
+
+func (a *App) signup(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form := userForm{
+		Username:    strings.TrimSpace(r.PostForm.Get("username")),
+		FieldErrors: make(map[string]string),
+	}
+	password := r.PostForm.Get("password")
+
+	if form.Username == "" {
+		form.FieldErrors["username"] = "This field is required."
+	} else if utf8.RuneCountInString(form.Username) > 50 {
+		form.FieldErrors["username"] = "Usernames may be at most 50 characters long."
+	}
+
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		form.FieldErrors["password"] = "Passwords must be at least 8 characters long."
+	}
+
+	if !form.Valid() {
+		data := a.newTemplateData(r)
+		data.Form = form
+		a.render(w, r, http.StatusUnprocessableEntity, "signup.html.tmpl", data)
+		return
+	}
+
+	userID, err := a.userModel.Insert(r.Context(), form.Username, password)
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicateUsername) {
+			form.FieldErrors["username"] = "That username is already taken."
+
+			data := a.newTemplateData(r)
+			data.Form = form
+			a.render(w, r, http.StatusUnprocessableEntity, "signup.html.tmpl", data)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to create user.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	if err := a.startUserSession(r, userID); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to start session.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (a *App) loginForm(w http.ResponseWriter, r *http.Request) {
+	data := a.newTemplateData(r)
+	data.Form = userForm{Next: r.URL.Query().Get("next")}
+
+	a.render(w, r, http.StatusOK, "login.html.tmpl", data)
+}
+
+func (a *App) login(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form := userForm{
+		Username: strings.TrimSpace(r.PostForm.Get("username")),
+		Next:     r.PostForm.Get("next"),
+	}
+
+	userID, err := a.userModel.Authenticate(r.Context(), form.Username, r.PostForm.Get("password"))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			form.NonFieldError = "Username or password is incorrect."
+
+			data := a.newTemplateData(r)
+			data.Form = form
+			a.render(w, r, http.StatusUnprocessableEntity, "login.html.tmpl", data)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to authenticate user.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	if err := a.startUserSession(r, userID); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to start session.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, safeRedirectTarget(form.Next), http.StatusSeeOther)
+}
+
+func (a *App) logout(w http.ResponseWriter, r *http.Request) {
+	// Renewing the token on any change in privilege level prevents session fixation.
+	if err := a.sessions.RenewToken(r.Context()); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to renew session token.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	a.sessions.Remove(r.Context(), authenticatedUserIDKey)
+
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+func (a *App) startUserSession(r *http.Request, userID int32) error {
+	// Renewing the token on any change in privilege level prevents session fixation.
+	if err := a.sessions.RenewToken(r.Context()); err != nil {
+		return err
+	}
+
+	a.sessions.Put(r.Context(), authenticatedUserIDKey, userID)
+
+	return nil
+}
+
+// safeRedirectTarget only allows redirects to paths on this site so the login form cannot be used
+// as an open redirect.
+func safeRedirectTarget(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+
+	return next
+}
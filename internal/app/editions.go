@@ -0,0 +1,82 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+// compareEditions shows what changed between an earlier edition of an ACS, given by the "from"
+// query parameter, and the edition in the path.
+func (a *App) compareEditions(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	acsID := r.PathValue("acs")
+	fromID := r.URL.Query().Get("from")
+
+	if fromID == "" {
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	acs, err := a.acsModel.GetACS(r.Context(), user.ID, acsID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve ACS.", "error", err, "acs", acsID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	from, err := a.acsModel.GetACS(r.Context(), user.ID, fromID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve ACS.", "error", err, "acs", fromID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	if from.Code != acs.Code {
+		a.notFound(w, r)
+		return
+	}
+
+	changes, err := a.acsModel.CompareEditions(r.Context(), from.ID, acs.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to compare editions.", "error", err, "from", from.ID, "to", acs.ID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.ACS = acs
+	data.CompareACS = from
+	data.EditionChanges = changes
+
+	a.render(w, r, http.StatusOK, "acs-compare.html.tmpl", data)
+}
+
+// adminSetCurrentEdition makes the edition in the path the current edition of its ACS.
+func (a *App) adminSetCurrentEdition(w http.ResponseWriter, r *http.Request) {
+	acsID := r.PathValue("acs")
+
+	if err := a.acsModel.SetCurrentEdition(r.Context(), acsID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to set current edition.", "error", err, "acs", acsID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, acsEditorURL(r), http.StatusSeeOther)
+}
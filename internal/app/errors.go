@@ -1,23 +1,83 @@
 package app
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"runtime/debug"
 )
 
-func (a *App) serverError(w http.ResponseWriter, _ *http.Request, err error) {
+// errorPage describes an error shown to the user.
+type errorPage struct {
+	Status  int
+	Title   string
+	Message string
+
+	// Detail is only shown when running in debug mode.
+	Detail string
+}
+
+// errorMessages holds friendlier explanations for common statuses. Statuses without an entry fall
+// back to a generic message.
+var errorMessages = map[int]string{
+	http.StatusBadRequest:          "The request could not be understood. Check the form and try again.",
+	http.StatusForbidden:           "You don't have permission to view this page.",
+	http.StatusNotFound:            "The page you requested doesn't exist. It may have been removed, or the link may be wrong.",
+	http.StatusInternalServerError: "Something went wrong on our end. Please try again later.",
+}
+
+func (a *App) serverError(w http.ResponseWriter, r *http.Request, err error) {
+	page := newErrorPage(http.StatusInternalServerError)
 	if a.debug {
-		trace := debug.Stack()
-		body := fmt.Sprintf("%s\n%s", err.Error(), trace)
-		http.Error(w, body, http.StatusInternalServerError)
-		return
+		page.Detail = fmt.Sprintf("%s\n%s", err.Error(), debug.Stack())
 	}
 
-	a.genericError(w, http.StatusInternalServerError)
+	a.renderError(w, r, page)
 }
 
-// genericError writes a response containing the generic text for the provided status code.
-func (a *App) genericError(w http.ResponseWriter, status int) {
-	http.Error(w, http.StatusText(status), status)
+// genericError renders the error page containing the generic text for the provided status code.
+func (a *App) genericError(w http.ResponseWriter, r *http.Request, status int) {
+	a.renderError(w, r, newErrorPage(status))
+}
+
+// notFound renders the error page for a missing resource.
+func (a *App) notFound(w http.ResponseWriter, r *http.Request) {
+	a.genericError(w, r, http.StatusNotFound)
+}
+
+func newErrorPage(status int) errorPage {
+	message, ok := errorMessages[status]
+	if !ok {
+		message = "The request could not be completed."
+	}
+
+	return errorPage{
+		Status:  status,
+		Title:   http.StatusText(status),
+		Message: message,
+	}
+}
+
+// renderError writes the themed error page. Unlike render, it can't report a failure by rendering
+// another page, so it falls back to a plain text response if the template fails.
+func (a *App) renderError(w http.ResponseWriter, r *http.Request, page errorPage) {
+	data := a.newTemplateData(r)
+	data.Error = page
+
+	buf := new(bytes.Buffer)
+	if err := a.templates.Render(buf, "error.html.tmpl", data); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to render error page.", "error", err, "status", page.Status)
+
+		body := page.Title
+		if page.Detail != "" {
+			body = fmt.Sprintf("%s\n%s", body, page.Detail)
+		}
+
+		http.Error(w, body, page.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(page.Status)
+	buf.WriteTo(w)
 }
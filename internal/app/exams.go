@@ -0,0 +1,206 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+const (
+	defaultExamTasksPerArea = 1
+	maxExamTasksPerArea     = 5
+	maxExamScenarioLength   = 2000
+)
+
+type examForm struct {
+	ACS          string
+	Student      string
+	Seed         string
+	TasksPerArea int
+	Scenario     string
+
+	FieldErrors map[string]string
+}
+
+func (a *App) examList(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	a.renderExamList(w, r, http.StatusOK, examForm{Student: user.Username, TasksPerArea: defaultExamTasksPerArea})
+}
+
+// renderExamList renders the user's exams along with the form to generate a new one.
+func (a *App) renderExamList(w http.ResponseWriter, r *http.Request, status int, form examForm) {
+	user, _ := a.currentUser(r)
+
+	documents, err := a.acsModel.ListACS(r.Context(), user.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to list ACS documents.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	exams, err := a.acsModel.ListExams(r.Context(), user.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to list exams.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.Form = form
+	data.ACSOptions = documents
+	data.Exams = exams
+
+	a.render(w, r, status, "exams.html.tmpl", data)
+}
+
+func (a *App) createExam(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form := examForm{
+		ACS:         r.PostForm.Get("acs"),
+		Student:     strings.TrimSpace(r.PostForm.Get("student")),
+		Seed:        strings.TrimSpace(r.PostForm.Get("seed")),
+		Scenario:    strings.TrimSpace(r.PostForm.Get("scenario")),
+		FieldErrors: make(map[string]string),
+	}
+
+	opts := models.ExamOptions{ACS: form.ACS, Scenario: form.Scenario}
+
+	tasksPerArea, err := strconv.Atoi(r.PostForm.Get("tasks_per_area"))
+	if err != nil || tasksPerArea < 1 || tasksPerArea > maxExamTasksPerArea {
+		form.FieldErrors["tasks_per_area"] = fmt.Sprintf("Pick between 1 and %d tasks per area.", maxExamTasksPerArea)
+	}
+
+	form.TasksPerArea = tasksPerArea
+	opts.TasksPerArea = tasksPerArea
+
+	if form.Seed == "" {
+		opts.Seed = rand.Int64N(1_000_000)
+	} else {
+		opts.Seed, err = strconv.ParseInt(form.Seed, 10, 64)
+		if err != nil {
+			form.FieldErrors["seed"] = "The seed must be a whole number."
+		}
+	}
+
+	if utf8.RuneCountInString(form.Scenario) > maxExamScenarioLength {
+		form.FieldErrors["scenario"] = fmt.Sprintf("The scenario can't be longer than %d characters.", maxExamScenarioLength)
+	}
+
+	// Users can examine themselves or one of their assigned students. Anyone else is reported as
+	// missing, the same as the student pages.
+	student := user
+	if form.Student != "" && form.Student != user.Username {
+		student, err = a.userModel.GetStudentByUsername(r.Context(), user.ID, form.Student)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				a.notFound(w, r)
+				return
+			}
+
+			a.logger.ErrorContext(r.Context(), "Failed to look up student.", "error", err, "student", form.Student)
+			a.serverError(w, r, err)
+			return
+		}
+	}
+
+	if len(form.FieldErrors) > 0 {
+		a.renderExamList(w, r, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	examID, err := a.acsModel.CreateExam(r.Context(), user.ID, student.ID, opts)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			form.FieldErrors["acs"] = "Pick an ACS that has been loaded."
+			a.renderExamList(w, r, http.StatusUnprocessableEntity, form)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to create exam.", "error", err, "acs", form.ACS)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/exams/%d", examID), http.StatusSeeOther)
+}
+
+func (a *App) examDetail(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	examID, err := strconv.ParseInt(r.PathValue("examID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return
+	}
+
+	exam, err := a.acsModel.GetExam(r.Context(), user.ID, int32(examID))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve exam.", "error", err, "examID", examID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.Exam = exam
+
+	a.render(w, r, http.StatusOK, "exam.html.tmpl", data)
+}
+
+func (a *App) gradeExamElement(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	examID, err := strconv.ParseInt(r.PathValue("examID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return
+	}
+
+	elementID, err := strconv.ParseInt(r.PathValue("elementID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	grade, err := models.ParseExamGrade(r.PostForm.Get("grade"))
+	if err != nil {
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = a.acsModel.GradeExamElement(r.Context(), user.ID, int32(examID), int32(elementID), grade)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to grade exam element.", "error", err, "examID", examID, "elementID", elementID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/exams/%d#element-%d", examID, elementID), http.StatusSeeOther)
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+// stubTemplates renders the name of the requested page so handlers can be tested without the
+// real templates.
+type stubTemplates struct{}
+
+func (stubTemplates) Render(w io.Writer, template string, data templateData) error {
+	_, err := io.WriteString(w, template)
+	return err
+}
+
+// examUsers resolves the students assigned to a single instructor.
+type examUsers struct {
+	userModel
+
+	instructorID int32
+	students     map[string]models.User
+}
+
+func (m *examUsers) GetStudentByUsername(ctx context.Context, instructorID int32, username string) (models.User, error) {
+	student, ok := m.students[username]
+	if !ok || instructorID != m.instructorID {
+		return models.User{}, &models.NotFoundError{Kind: "student", ID: username}
+	}
+
+	return student, nil
+}
+
+// examRecorder records the exams that are created.
+type examRecorder struct {
+	acsModel
+
+	examinerIDs []int32
+	studentIDs  []int32
+}
+
+func (m *examRecorder) CreateExam(ctx context.Context, examinerID int32, studentID int32, opts models.ExamOptions) (int32, error) {
+	m.examinerIDs = append(m.examinerIDs, examinerID)
+	m.studentIDs = append(m.studentIDs, studentID)
+
+	return 1, nil
+}
+
+func TestCreateExam(t *testing.T) {
+	instructor := models.User{ID: 1, Username: "instructor"}
+	student := models.User{ID: 2, Username: "student"}
+	other := models.User{ID: 3, Username: "other"}
+
+	testCases := []struct {
+		name        string
+		user        models.User
+		student     string
+		wantStatus  int
+		wantStudent int32
+	}{
+		{
+			name:        "assigned student",
+			user:        instructor,
+			student:     student.Username,
+			wantStatus:  http.StatusSeeOther,
+			wantStudent: student.ID,
+		},
+		{
+			name:        "self",
+			user:        other,
+			student:     other.Username,
+			wantStatus:  http.StatusSeeOther,
+			wantStudent: other.ID,
+		},
+		{
+			name:        "self by default",
+			user:        other,
+			wantStatus:  http.StatusSeeOther,
+			wantStudent: other.ID,
+		},
+		{
+			name:       "unassigned user",
+			user:       other,
+			student:    student.Username,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "unknown user",
+			user:       instructor,
+			student:    "nobody",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exams := &examRecorder{}
+			a := &App{
+				logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+				templates: stubTemplates{},
+				acsModel:  exams,
+				userModel: &examUsers{
+					instructorID: instructor.ID,
+					students:     map[string]models.User{student.Username: student},
+				},
+			}
+
+			form := url.Values{
+				"acs":            {"PA"},
+				"student":        {tc.student},
+				"tasks_per_area": {"1"},
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/exams", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r = r.WithContext(context.WithValue(r.Context(), userContextKey, tc.user))
+			w := httptest.NewRecorder()
+
+			a.createExam(w, r)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, w.Code)
+			}
+
+			if tc.wantStatus != http.StatusSeeOther {
+				if len(exams.studentIDs) != 0 {
+					t.Errorf("expected no exam to be created, got exams for students %v", exams.studentIDs)
+				}
+
+				return
+			}
+
+			if len(exams.studentIDs) != 1 {
+				t.Fatalf("expected 1 exam to be created, got %d", len(exams.studentIDs))
+			}
+
+			if exams.studentIDs[0] != tc.wantStudent {
+				t.Errorf("expected exam for student %d, got %d", tc.wantStudent, exams.studentIDs[0])
+			}
+
+			if exams.examinerIDs[0] != tc.user.ID {
+				t.Errorf("expected examiner %d, got %d", tc.user.ID, exams.examinerIDs[0])
+			}
+		})
+	}
+}
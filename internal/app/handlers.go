@@ -6,52 +6,186 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/cdriehuys/flight-school/internal/models"
 )
 
 func (a *App) homepage(w http.ResponseWriter, r *http.Request) {
-	areas, err := a.acsModel.ListAreasByACS(r.Context(), "PA")
+	user, _ := a.currentUser(r)
+
+	documents, err := a.acsModel.ListACS(r.Context(), user.ID)
 	if err != nil {
-		a.logger.Error("Failed to list ACS areas.", "error", err)
+		a.logger.Error("Failed to list ACS documents.", "error", err)
 		a.serverError(w, r, err)
 		return
 	}
 
-	data := templateData{AreasOfOperation: areas}
+	data := a.newTemplateData(r)
+	for _, acs := range documents {
+		// Older editions are only listed on the page for the ACS unless the user is studying them.
+		if acs.Studying {
+			data.StudyingACS = append(data.StudyingACS, acs)
+		} else if acs.Current {
+			data.OtherACS = append(data.OtherACS, acs)
+		}
+	}
 
 	a.render(w, r, http.StatusOK, "index.html.tmpl", data)
 }
 
+func (a *App) acsDetail(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	acsID := r.PathValue("acs")
+
+	acs, err := a.acsModel.GetACS(r.Context(), user.ID, acsID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.Error("Failed to retrieve ACS.", "error", err, "acs", acsID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	areas, err := a.acsModel.ListAreasByACS(r.Context(), user.ID, acs.ID)
+	if err != nil {
+		a.logger.Error("Failed to list ACS areas.", "error", err, "acs", acs.ID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	editions, err := a.acsModel.ListEditions(r.Context(), acs.ID)
+	if err != nil {
+		a.logger.Error("Failed to list ACS editions.", "error", err, "acs", acs.ID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.ACS = acs
+	data.AreasOfOperation = areas
+	data.Editions = editions
+
+	a.render(w, r, http.StatusOK, "acs-detail.html.tmpl", data)
+}
+
+func (a *App) setStudying(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	acsID := r.PathValue("acs")
+
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	studying, err := strconv.ParseBool(r.PostForm.Get("studying"))
+	if err != nil {
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	if err := a.acsModel.SetStudying(r.Context(), user.ID, acsID, studying); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to update studied ACS.", "error", err, "acs", acsID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, safeRedirectTarget(r.PostForm.Get("next")), http.StatusSeeOther)
+}
+
+// setClassRating changes the class rating the current user is training for. An empty rating
+// includes tasks for every class.
+func (a *App) setClassRating(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	var rating *models.ClassRating
+	if raw := r.PostForm.Get("classRating"); raw != "" {
+		parsed, err := models.ParseClassRating(raw)
+		if err != nil {
+			a.genericError(w, r, http.StatusBadRequest)
+			return
+		}
+
+		rating = &parsed
+	}
+
+	if err := a.userModel.SetClassRating(r.Context(), user.ID, rating); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to update class rating.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
 func (a *App) areaDetail(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
 	acs := r.PathValue("acs")
 	areaID := r.PathValue("areaID")
 	area, err := a.acsModel.GetAreaByID(r.Context(), acs, areaID)
 	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
 		a.logger.Error("Failed to retrieve ACS area.", "error", err)
 		a.serverError(w, r, err)
 		return
 	}
 
-	tasks, err := a.acsModel.ListTasksByArea(r.Context(), area.ID)
+	tasks, err := a.acsModel.ListTasksByArea(r.Context(), user.ID, area.ID)
 	if err != nil {
 		a.logger.Error("Failed to list tasks for area.", "error", err, "acs", acs, "area", area.PublicID)
 		a.serverError(w, r, err)
 		return
 	}
 
-	data := templateData{AreaOfOperation: area, Tasks: tasks}
+	history, err := a.acsModel.GetAreaConfidenceHistory(r.Context(), user.ID, area.ID)
+	if err != nil {
+		a.logger.Error("Failed to retrieve area confidence history.", "error", err, "acs", acs, "area", area.PublicID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.AreaOfOperation = area
+	data.Tasks = tasks
+	data.ConfidenceHistory = history
 
 	a.render(w, r, http.StatusOK, "area-detail.html.tmpl", data)
 }
 
 func (a *App) taskDetail(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
 	acs := r.PathValue("acs")
 	areaID := r.PathValue("areaID")
 	taskID := r.PathValue("taskID")
 
-	task, err := a.acsModel.GetTaskByArea(r.Context(), acs, areaID, taskID)
+	task, err := a.acsModel.GetTaskByArea(r.Context(), user.ID, acs, areaID, taskID)
 	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
 		a.logger.ErrorContext(
 			r.Context(),
 			"Failed to retrieve task.",
@@ -62,7 +196,7 @@ func (a *App) taskDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	confidence, err := a.acsModel.GetTaskConfidence(r.Context(), task.ID)
+	confidence, err := a.acsModel.GetTaskConfidence(r.Context(), user.ID, task.ID)
 	if err != nil {
 		a.logger.ErrorContext(
 			r.Context(),
@@ -74,58 +208,81 @@ func (a *App) taskDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data := templateData{Task: task, TaskConfidence: confidence}
+	history, err := a.acsModel.GetTaskConfidenceHistory(r.Context(), user.ID, task.ID)
+	if err != nil {
+		a.logger.ErrorContext(
+			r.Context(),
+			"Failed to retrieve task confidence history.",
+			"error", err,
+			"taskID", task.ID,
+		)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.Task = task
+	data.TaskConfidence = confidence
+	data.ConfidenceHistory = history
 
 	a.render(w, r, http.StatusOK, "task-detail.html.tmpl", data)
 }
 
 func (a *App) setElementConfidence(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
 	elementID, err := strconv.ParseInt(r.PathValue("elementID"), 10, 32)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprint(w, http.StatusText(http.StatusBadRequest))
+		a.notFound(w, r)
+		return
+	}
+
+	redirectTarget, err := a.elementRedirectTarget(r, user.ID, int32(elementID))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to look up element.", "error", err, "elementID", elementID)
+		a.serverError(w, r, err)
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
 		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
-		a.serverError(w, r, err)
+		a.genericError(w, r, http.StatusBadRequest)
 		return
 	}
 
 	confidence, err := getConfidenceFromForm(r.PostForm)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprint(w, "Unknown confidence level")
+		a.genericError(w, r, http.StatusBadRequest)
 		return
 	}
 
-	if err := a.acsModel.SetElementConfidence(r.Context(), int32(elementID), confidence); err != nil {
+	if err := a.acsModel.SetElementConfidence(r.Context(), user.ID, int32(elementID), confidence); err != nil {
 		a.logger.ErrorContext(r.Context(), "Failed to set element confidence.", "error", err, "elementID", elementID, "confidence", confidence)
 		a.serverError(w, r, err)
 		return
 	}
 
-	task, err := a.acsModel.GetTaskByElementID(r.Context(), int32(elementID))
-	if err != nil {
-		a.logger.ErrorContext(r.Context(), "Failed to retrieve parent task.", "error", err, "elementID", elementID)
-		a.serverError(w, r, err)
-		return
-	}
+	http.Redirect(w, r, redirectTarget, http.StatusSeeOther)
+}
 
-	elementPublicID, err := a.acsModel.GetElementPublicIDByID(r.Context(), int32(elementID))
+func (a *App) reviewQueue(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	reviews, err := a.acsModel.ListDueReviews(r.Context(), user.ID, time.Now())
 	if err != nil {
-		a.logger.ErrorContext(r.Context(), "Failed to retrieve public ID for element.", "error", err, "elementID", elementID)
+		a.logger.ErrorContext(r.Context(), "Failed to list due reviews.", "error", err)
 		a.serverError(w, r, err)
 		return
 	}
 
-	http.Redirect(
-		w,
-		r,
-		fmt.Sprintf("/acs/%s/%s/%s#%s", task.Area.ACS, task.Area.PublicID, task.PublicID, elementPublicID),
-		http.StatusSeeOther,
-	)
+	data := a.newTemplateData(r)
+	data.Reviews = reviews
+
+	a.render(w, r, http.StatusOK, "review.html.tmpl", data)
 }
 
 func getConfidenceFromForm(values url.Values) (models.ConfidenceLevel, error) {
@@ -145,37 +302,104 @@ func getConfidenceFromForm(values url.Values) (models.ConfidenceLevel, error) {
 }
 
 func (a *App) clearElementConfidence(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
 	elementID, err := strconv.ParseInt(r.PathValue("elementID"), 10, 32)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprint(w, http.StatusText(http.StatusBadRequest))
+		a.notFound(w, r)
 		return
 	}
 
-	if err := a.acsModel.ClearElementConfidence(r.Context(), int32(elementID)); err != nil {
+	redirectTarget, err := a.elementRedirectTarget(r, user.ID, int32(elementID))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to look up element.", "error", err, "elementID", elementID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	if err := a.acsModel.ClearElementConfidence(r.Context(), user.ID, int32(elementID)); err != nil {
 		a.logger.ErrorContext(r.Context(), "Failed to set element confidence.", "error", err, "elementID", elementID)
 		a.serverError(w, r, err)
 		return
 	}
 
-	task, err := a.acsModel.GetTaskByElementID(r.Context(), int32(elementID))
+	http.Redirect(w, r, redirectTarget, http.StatusSeeOther)
+}
+
+func (a *App) setElementNote(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	elementID, err := strconv.ParseInt(r.PathValue("elementID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return
+	}
+
+	redirectTarget, err := a.elementRedirectTarget(r, user.ID, int32(elementID))
 	if err != nil {
-		a.logger.ErrorContext(r.Context(), "Failed to retrieve parent task.", "error", err, "elementID", elementID)
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to look up element.", "error", err, "elementID", elementID)
 		a.serverError(w, r, err)
 		return
 	}
 
-	elementPublicID, err := a.acsModel.GetElementPublicIDByID(r.Context(), int32(elementID))
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	content := r.PostForm.Get("note")
+	if utf8.RuneCountInString(strings.TrimSpace(content)) > models.MaxNoteLength {
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	if rawOrder := r.PostForm.Get("sub_element"); rawOrder != "" {
+		order, parseErr := strconv.ParseInt(rawOrder, 10, 32)
+		if parseErr != nil {
+			a.genericError(w, r, http.StatusBadRequest)
+			return
+		}
+
+		err = a.acsModel.SetSubElementNote(r.Context(), user.ID, int32(elementID), int32(order), content)
+	} else {
+		err = a.acsModel.SetElementNote(r.Context(), user.ID, int32(elementID), content)
+	}
+
 	if err != nil {
-		a.logger.ErrorContext(r.Context(), "Failed to retrieve public ID for element.", "error", err, "elementID", elementID)
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to save note.", "error", err, "elementID", elementID)
 		a.serverError(w, r, err)
 		return
 	}
 
-	http.Redirect(
-		w,
-		r,
-		fmt.Sprintf("/acs/%s/%s/%s#%s", task.Area.ACS, task.Area.PublicID, task.PublicID, elementPublicID),
-		http.StatusSeeOther,
-	)
+	http.Redirect(w, r, redirectTarget, http.StatusSeeOther)
+}
+
+// elementRedirectTarget builds the URL of an element within its task's page. It doubles as a check
+// that the element exists before it is modified.
+func (a *App) elementRedirectTarget(r *http.Request, userID int32, elementID int32) (string, error) {
+	task, err := a.acsModel.GetTaskByElementID(r.Context(), userID, elementID)
+	if err != nil {
+		return "", err
+	}
+
+	elementPublicID, err := a.acsModel.GetElementPublicIDByID(r.Context(), elementID)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("/acs/%s/%s/%s#%s", task.Area.ACS, task.Area.PublicID, task.PublicID, elementPublicID), nil
 }
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// markdownRenderer converts user notes to HTML. Raw HTML and links with unsafe schemes such as
+// javascript: are dropped because the renderer is not configured as unsafe.
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.Linkify, extension.Strikethrough),
+	goldmark.WithRendererOptions(html.WithHardWraps()),
+)
+
+// markdown renders markdown source as HTML. If rendering fails, the source is shown as escaped
+// text instead.
+func markdown(source string) template.HTML {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(source), &buf); err != nil {
+		return template.HTML(template.HTMLEscapeString(source))
+	}
+
+	return template.HTML(buf.String())
+}
@@ -1,8 +1,14 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models"
 )
 
 // logRequest logs at the beginning and end of each request. It includes the request duration, which
@@ -20,3 +26,115 @@ func (a *App) logRequest(next http.Handler) http.Handler {
 		logger.Info("Request completed", "duration", elapsed)
 	})
 }
+
+type contextKey string
+
+const userContextKey = contextKey("user")
+
+// authenticatedUserIDKey is the session key holding the ID of the signed in user.
+const authenticatedUserIDKey = "authenticatedUserID"
+
+// authenticate loads the signed in user, if any, into the request context. It must run after the
+// session has been loaded.
+func (a *App) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := a.sessions.GetInt32(r.Context(), authenticatedUserIDKey)
+		if id == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := a.userModel.GetByID(r.Context(), id)
+		if err != nil {
+			// The user may have been deleted since the session was created. Treat them as signed
+			// out rather than failing every request.
+			a.logger.WarnContext(r.Context(), "Failed to load user from session.", "error", err, "userID", id)
+			a.sessions.Remove(r.Context(), authenticatedUserIDKey)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userContextKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requireAuthentication redirects anonymous users to the login page. The page they were trying to
+// reach is preserved so they can be sent back after logging in.
+func (a *App) requireAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := a.currentUser(r); !ok {
+			loginURL := "/login?next=" + url.QueryEscape(r.URL.RequestURI())
+			http.Redirect(w, r, loginURL, http.StatusSeeOther)
+			return
+		}
+
+		// Pages that depend on the signed in user should not be cached.
+		w.Header().Add("Cache-Control", "no-store")
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAdmin rejects users who can't edit ACS content. It must run after requireAuthentication.
+func (a *App) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _ := a.currentUser(r); !user.IsAdmin {
+			a.genericError(w, r, http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireInstructor rejects users who aren't instructors. It must run after requireAuthentication.
+func (a *App) requireInstructor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _ := a.currentUser(r); !user.IsInstructor() {
+			a.genericError(w, r, http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticateAPIToken requires a bearer token and loads the user it belongs to into the request
+// context. API requests don't use sessions, so this takes the place of both authenticate and
+// requireAuthentication.
+func (a *App) authenticateAPIToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="flight-school"`)
+			a.apiError(w, r, http.StatusUnauthorized, "A bearer token is required.")
+			return
+		}
+
+		user, err := a.userModel.GetByAPIToken(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidAPIToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="flight-school", error="invalid_token"`)
+				a.apiError(w, r, http.StatusUnauthorized, "The bearer token is not valid.")
+				return
+			}
+
+			a.logger.ErrorContext(r.Context(), "Failed to authenticate API token.", "error", err)
+			a.apiServerError(w, r, err)
+			return
+		}
+
+		w.Header().Add("Cache-Control", "no-store")
+
+		ctx := context.WithValue(r.Context(), userContextKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// currentUser returns the signed in user for the request.
+func (a *App) currentUser(r *http.Request) (models.User, bool) {
+	user, ok := r.Context().Value(userContextKey).(models.User)
+
+	return user, ok
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+const (
+	defaultPracticeTestSize = 10
+	maxPracticeTestSize     = 60
+)
+
+func (a *App) startPracticeTest(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	acsID := r.PostForm.Get("acs")
+	areaID := r.PostForm.Get("area")
+
+	count := defaultPracticeTestSize
+	if raw := r.PostForm.Get("count"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxPracticeTestSize {
+			a.genericError(w, r, http.StatusBadRequest)
+			return
+		}
+
+		count = parsed
+	}
+
+	testID, err := a.acsModel.StartPracticeTest(r.Context(), user.ID, acsID, areaID, count)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		if errors.Is(err, models.ErrNoQuestions) {
+			a.genericError(w, r, http.StatusUnprocessableEntity)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to start practice test.", "error", err, "acs", acsID, "area", areaID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/practice-tests/%d", testID), http.StatusSeeOther)
+}
+
+// practiceTest shows the questions on a practice test, or the results once it has been submitted.
+func (a *App) practiceTest(w http.ResponseWriter, r *http.Request) {
+	test, ok := a.requestPracticeTest(w, r)
+	if !ok {
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.PracticeTest = test
+
+	if test.CompletedAt == nil {
+		a.render(w, r, http.StatusOK, "practice-test.html.tmpl", data)
+	} else {
+		a.render(w, r, http.StatusOK, "practice-test-results.html.tmpl", data)
+	}
+}
+
+func (a *App) submitPracticeTest(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	test, ok := a.requestPracticeTest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	// Each question's choice is submitted under the question's ID. Skipped questions are absent.
+	answers := make(map[int32]int32)
+	for _, q := range test.Questions {
+		raw := r.PostForm.Get(fmt.Sprintf("question-%d", q.ID))
+		if raw == "" {
+			continue
+		}
+
+		choice, ok := q.ParseChoice(raw)
+		if !ok {
+			a.genericError(w, r, http.StatusBadRequest)
+			return
+		}
+
+		answers[q.ID] = choice
+	}
+
+	if err := a.acsModel.SubmitPracticeTest(r.Context(), user.ID, test.ID, answers); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to submit practice test.", "error", err, "testID", test.ID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/practice-tests/%d", test.ID), http.StatusSeeOther)
+}
+
+// requestPracticeTest loads the current user's practice test in the request path. It renders a 404
+// page and returns false if the test doesn't exist or belongs to someone else.
+func (a *App) requestPracticeTest(w http.ResponseWriter, r *http.Request) (models.PracticeTest, bool) {
+	user, _ := a.currentUser(r)
+
+	testID, err := strconv.ParseInt(r.PathValue("testID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return models.PracticeTest{}, false
+	}
+
+	test, err := a.acsModel.GetPracticeTest(r.Context(), user.ID, int32(testID))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return models.PracticeTest{}, false
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve practice test.", "error", err, "testID", testID)
+		a.serverError(w, r, err)
+		return models.PracticeTest{}, false
+	}
+
+	return test, true
+}
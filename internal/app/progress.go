@@ -0,0 +1,112 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+// maxProgressBytes limits the size of uploaded progress files. Notes make up most of a file, so
+// this leaves room for a note on every element of a few ACS documents.
+const maxProgressBytes = 10 << 20
+
+type progressForm struct {
+	FieldErrors map[string]string
+
+	// Result is set once a file has been imported.
+	Result *models.ProgressImport
+}
+
+func (a *App) progressPage(w http.ResponseWriter, r *http.Request) {
+	data := a.newTemplateData(r)
+	data.Form = progressForm{}
+
+	a.render(w, r, http.StatusOK, "progress.html.tmpl", data)
+}
+
+func (a *App) exportProgress(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	progress, err := a.acsModel.ExportProgress(r.Context(), user.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to export progress.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	body, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to encode progress.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("flight-school-%s-%s.json", user.Username, progress.ExportedAt.Format(time.DateOnly))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Write(body)
+}
+
+func (a *App) importProgress(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxProgressBytes)
+	if err := r.ParseMultipartForm(maxProgressBytes); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form := progressForm{FieldErrors: make(map[string]string)}
+
+	renderInvalid := func(message string) {
+		form.FieldErrors["progress"] = message
+
+		data := a.newTemplateData(r)
+		data.Form = form
+		a.render(w, r, http.StatusUnprocessableEntity, "progress.html.tmpl", data)
+	}
+
+	file, _, err := r.FormFile("progress")
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			renderInvalid("Choose a progress file to import.")
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to open uploaded progress file.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	defer file.Close()
+
+	var progress models.Progress
+	if err := json.NewDecoder(file).Decode(&progress); err != nil {
+		renderInvalid(fmt.Sprintf("The file isn't a progress export: %v", err))
+		return
+	}
+
+	if err := models.ValidateProgress(progress); err != nil {
+		renderInvalid(fmt.Sprintf("The file can't be imported: %v", err))
+		return
+	}
+
+	result, err := a.acsModel.ImportProgress(r.Context(), user.ID, progress)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to import progress.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	form.Result = &result
+
+	data := a.newTemplateData(r)
+	data.Form = form
+	a.render(w, r, http.StatusOK, "progress.html.tmpl", data)
+}
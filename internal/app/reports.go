@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/cdriehuys/flight-school/internal/report"
+)
+
+// acsReport returns a handler that downloads the user's confidence report for an ACS in a
+// particular format. The download is named after the ACS followed by the given suffix.
+func (a *App) acsReport(suffix string, contentType string, write func(io.Writer, report.Report) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, _ := a.currentUser(r)
+		acsID := r.PathValue("acs")
+
+		rep, err := report.Build(r.Context(), a.acsModel, user, acsID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				a.notFound(w, r)
+				return
+			}
+
+			a.logger.ErrorContext(r.Context(), "Failed to build report.", "error", err, "acs", acsID)
+			a.serverError(w, r, err)
+			return
+		}
+
+		filename := fmt.Sprintf("%s-%s", rep.ACS.ID, suffix)
+		a.writeReport(w, r, rep, filename, contentType, write)
+	}
+}
+
+// areaGuide downloads a printable study guide for a single area of operation.
+func (a *App) areaGuide(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	acsID := r.PathValue("acs")
+	areaID := r.PathValue("areaID")
+
+	rep, err := report.BuildArea(r.Context(), a.acsModel, user, acsID, areaID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to build area report.", "error", err, "acs", acsID, "area", areaID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-guide.pdf", rep.Area.FullID())
+	a.writeReport(w, r, rep, filename, "application/pdf", report.WritePDF)
+}
+
+// writeReport sends a report as a file download.
+func (a *App) writeReport(
+	w http.ResponseWriter,
+	r *http.Request,
+	rep report.Report,
+	filename string,
+	contentType string,
+	write func(io.Writer, report.Report) error,
+) {
+	// Write to a buffer first so that a failure doesn't leave a truncated download.
+	buf := new(bytes.Buffer)
+	if err := write(buf, rep); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to write report.", "error", err, "acs", rep.ACS.ID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	buf.WriteTo(w)
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"net/http"
 
+	"github.com/cdriehuys/flight-school/internal/report"
 	"github.com/justinas/alice"
 )
 
@@ -12,14 +13,105 @@ func (a *App) Routes() http.Handler {
 	mux := http.NewServeMux()
 	mux.Handle("GET /static/", http.StripPrefix("/static/", a.staticfiles))
 
-	mux.HandleFunc("GET /{$}", a.homepage)
+	dynamic := alice.New(a.sessions.LoadAndSave, a.authenticate)
+
+	mux.Handle("GET /signup", dynamic.ThenFunc(a.signupForm))
+	mux.Handle("POST /signup", dynamic.ThenFunc(a.signup))
+	mux.Handle("GET /login", dynamic.ThenFunc(a.loginForm))
+	mux.Handle("POST /login", dynamic.ThenFunc(a.login))
+
+	protected := dynamic.Append(a.requireAuthentication)
+
+	mux.Handle("POST /logout", protected.ThenFunc(a.logout))
+
+	mux.Handle("GET /{$}", protected.ThenFunc(a.homepage))
 	mux.Handle("GET /acs", homepageRedirect)
-	mux.Handle("GET /acs/{acs}", homepageRedirect)
-	mux.HandleFunc("GET /acs/{acs}/{areaID}", a.areaDetail)
-	mux.HandleFunc("GET /acs/{acs}/{areaID}/{taskID}", a.taskDetail)
+	mux.Handle("GET /acs/{acs}", protected.ThenFunc(a.acsDetail))
+	mux.Handle("POST /acs/{acs}/studying", protected.ThenFunc(a.setStudying))
+	mux.Handle("POST /class-rating", protected.ThenFunc(a.setClassRating))
+	mux.Handle("GET /acs/{acs}/compare", protected.ThenFunc(a.compareEditions))
+	mux.Handle("GET /acs/{acs}/report.csv", protected.Then(a.acsReport("report.csv", "text/csv; charset=utf-8", report.WriteCSV)))
+	mux.Handle("GET /acs/{acs}/report.md", protected.Then(a.acsReport("report.md", "text/markdown; charset=utf-8", report.WriteMarkdown)))
+	mux.Handle("GET /acs/{acs}/guide.pdf", protected.Then(a.acsReport("guide.pdf", "application/pdf", report.WritePDF)))
+	mux.Handle("GET /acs/{acs}/{areaID}", protected.ThenFunc(a.areaDetail))
+	mux.Handle("GET /acs/{acs}/{areaID}/guide.pdf", protected.ThenFunc(a.areaGuide))
+	mux.Handle("GET /acs/{acs}/{areaID}/{taskID}", protected.ThenFunc(a.taskDetail))
+
+	mux.Handle("GET /search", protected.ThenFunc(a.search))
+
+	mux.Handle("GET /review", protected.ThenFunc(a.reviewQueue))
+
+	mux.Handle("POST /study", protected.ThenFunc(a.startStudySession))
+	mux.Handle("GET /study/{sessionID}", protected.ThenFunc(a.studySession))
+	mux.Handle("POST /study/{sessionID}/elements/{elementID}", protected.ThenFunc(a.rateStudyElement))
+	mux.Handle("POST /study/{sessionID}/end", protected.ThenFunc(a.endStudySession))
+
+	mux.Handle("POST /practice-tests", protected.ThenFunc(a.startPracticeTest))
+	mux.Handle("GET /practice-tests/{testID}", protected.ThenFunc(a.practiceTest))
+	mux.Handle("POST /practice-tests/{testID}", protected.ThenFunc(a.submitPracticeTest))
+
+	mux.Handle("GET /exams", protected.ThenFunc(a.examList))
+	mux.Handle("POST /exams", protected.ThenFunc(a.createExam))
+	mux.Handle("GET /exams/{examID}", protected.ThenFunc(a.examDetail))
+	mux.Handle("POST /exams/{examID}/elements/{elementID}", protected.ThenFunc(a.gradeExamElement))
+
+	mux.Handle("GET /knowledge-test", protected.ThenFunc(a.knowledgeTestForm))
+	mux.Handle("POST /knowledge-test", protected.ThenFunc(a.importKnowledgeTest))
+
+	mux.Handle("GET /progress", protected.ThenFunc(a.progressPage))
+	mux.Handle("GET /progress/export", protected.ThenFunc(a.exportProgress))
+	mux.Handle("POST /progress/import", protected.ThenFunc(a.importProgress))
+
+	mux.Handle("POST /task-elements/{elementID}/confidence", protected.ThenFunc(a.setElementConfidence))
+	mux.Handle("POST /task-elements/{elementID}/clear-confidence", protected.ThenFunc(a.clearElementConfidence))
+	mux.Handle("POST /task-elements/{elementID}/note", protected.ThenFunc(a.setElementNote))
+
+	instructor := protected.Append(a.requireInstructor)
+
+	mux.Handle("GET /students", instructor.ThenFunc(a.studentDashboard))
+	mux.Handle("GET /students/{studentID}/acs/{acs}/{areaID}", instructor.ThenFunc(a.studentArea))
+	mux.Handle("GET /students/{studentID}/acs/{acs}/{areaID}/{taskID}", instructor.ThenFunc(a.studentTask))
+	mux.Handle("POST /students/{studentID}/elements/{elementID}/sign-off", instructor.ThenFunc(a.signOffElement))
+	mux.Handle("POST /students/{studentID}/elements/{elementID}/remove-sign-off", instructor.ThenFunc(a.removeElementSignOff))
+
+	admin := protected.Append(a.requireAdmin)
+
+	mux.Handle("GET /admin", admin.ThenFunc(a.adminHome))
+	mux.Handle("GET /admin/acs/{acs}", admin.ThenFunc(a.acsEditor))
+	mux.Handle("POST /admin/acs/{acs}", admin.ThenFunc(a.adminUpdateACS))
+	mux.Handle("GET /admin/acs/{acs}/export", admin.ThenFunc(a.adminExportACS))
+	mux.Handle("POST /admin/acs/{acs}/areas", admin.ThenFunc(a.adminAddArea))
+	mux.Handle("POST /admin/acs/{acs}/current", admin.ThenFunc(a.adminSetCurrentEdition))
+	mux.Handle("GET /admin/acs/{acs}/{areaID}", admin.ThenFunc(a.acsEditor))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}", admin.ThenFunc(a.adminUpdateArea))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}/move", admin.ThenFunc(a.adminMoveArea))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}/delete", admin.ThenFunc(a.adminRemoveArea))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}/tasks", admin.ThenFunc(a.adminAddTask))
+	mux.Handle("GET /admin/acs/{acs}/{areaID}/{taskID}", admin.ThenFunc(a.acsEditor))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}/{taskID}", admin.ThenFunc(a.adminUpdateTask))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}/{taskID}/move", admin.ThenFunc(a.adminMoveTask))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}/{taskID}/delete", admin.ThenFunc(a.adminRemoveTask))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}/{taskID}/elements", admin.ThenFunc(a.adminAddElement))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}/{taskID}/elements/{elementID}", admin.ThenFunc(a.adminUpdateElement))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}/{taskID}/elements/{elementID}/move", admin.ThenFunc(a.adminMoveElement))
+	mux.Handle("POST /admin/acs/{acs}/{areaID}/{taskID}/elements/{elementID}/delete", admin.ThenFunc(a.adminRemoveElement))
+
+	api := alice.New(a.authenticateAPIToken)
+
+	mux.HandleFunc("GET /api/v1/openapi.json", a.apiOpenAPIDocument)
+	mux.Handle("GET /api/v1/acs", api.ThenFunc(a.apiListACS))
+	mux.Handle("GET /api/v1/acs/{acs}", api.ThenFunc(a.apiGetACS))
+	mux.Handle("GET /api/v1/acs/{acs}/areas/{areaID}", api.ThenFunc(a.apiGetArea))
+	mux.Handle("GET /api/v1/acs/{acs}/areas/{areaID}/tasks/{taskID}", api.ThenFunc(a.apiGetTask))
+	mux.Handle("GET /api/v1/progress", api.ThenFunc(a.apiExportProgress))
+	mux.Handle("POST /api/v1/progress", api.ThenFunc(a.apiImportProgress))
+	mux.Handle("GET /api/v1/search", api.ThenFunc(a.apiSearch))
+	mux.Handle("GET /api/v1/elements/{elementID}", api.ThenFunc(a.apiGetElement))
+	mux.Handle("PUT /api/v1/elements/{elementID}/confidence", api.ThenFunc(a.apiSetElementConfidence))
+	mux.Handle("DELETE /api/v1/elements/{elementID}/confidence", api.ThenFunc(a.apiClearElementConfidence))
+	mux.HandleFunc("/api/", a.apiNotFound)
 
-	mux.HandleFunc("POST /task-elements/{elementID}/confidence", a.setElementConfidence)
-	mux.HandleFunc("POST /task-elements/{elementID}/clear-confidence", a.clearElementConfidence)
+	mux.Handle("/", dynamic.ThenFunc(a.notFound))
 
 	middleware := alice.New(a.logRequest)
 
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSearchQueryLength limits the length of search queries in characters.
+const maxSearchQueryLength = 200
+
+type searchForm struct {
+	Query string
+	ACS   string
+
+	FieldErrors map[string]string
+}
+
+// parseSearchForm reads a search from the query string. The boolean return value is false if
+// there is nothing to search for.
+func parseSearchForm(r *http.Request) (searchForm, bool) {
+	form := searchForm{
+		Query:       strings.TrimSpace(r.URL.Query().Get("q")),
+		ACS:         r.URL.Query().Get("acs"),
+		FieldErrors: make(map[string]string),
+	}
+
+	if form.Query == "" {
+		return form, false
+	}
+
+	if utf8.RuneCountInString(form.Query) > maxSearchQueryLength {
+		form.FieldErrors["q"] = "Search must be at most 200 characters."
+		return form, false
+	}
+
+	return form, true
+}
+
+func (a *App) search(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	documents, err := a.acsModel.ListACS(r.Context(), user.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to list ACS documents.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.ACSOptions = documents
+
+	form, ok := parseSearchForm(r)
+	data.Form = form
+
+	if ok {
+		results, err := a.acsModel.Search(r.Context(), user.ID, form.Query, form.ACS)
+		if err != nil {
+			a.logger.ErrorContext(r.Context(), "Failed to search.", "error", err, "query", form.Query)
+			a.serverError(w, r, err)
+			return
+		}
+
+		data.SearchResults = results
+	}
+
+	status := http.StatusOK
+	if len(form.FieldErrors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	a.render(w, r, status, "search.html.tmpl", data)
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+const (
+	sparklineWidth   = 240
+	sparklineHeight  = 48
+	sparklinePadding = 4
+)
+
+// sparkline renders confidence history as an inline SVG line chart. Each point is the confidence
+// percentage at the end of a week.
+func sparkline(history []models.ConfidenceSnapshot) template.HTML {
+	if len(history) == 0 {
+		return ""
+	}
+
+	innerWidth := float64(sparklineWidth - 2*sparklinePadding)
+	innerHeight := float64(sparklineHeight - 2*sparklinePadding)
+
+	step := 0.0
+	if len(history) > 1 {
+		step = innerWidth / float64(len(history)-1)
+	}
+
+	points := make([]string, len(history))
+	var lastX, lastY float64
+	for i, snapshot := range history {
+		percent := fracAsPercent(snapshot.Confidence.Votes, snapshot.Confidence.Possible)
+
+		lastX = sparklinePadding + step*float64(i)
+		lastY = sparklinePadding + innerHeight*(1-float64(percent)/100)
+		points[i] = fmt.Sprintf("%.1f,%.1f", lastX, lastY)
+	}
+
+	first := history[0]
+	last := history[len(history)-1]
+	label := fmt.Sprintf(
+		"Confidence went from %d%% the week of %s to %d%% the week of %s",
+		fracAsPercent(first.Confidence.Votes, first.Confidence.Possible),
+		formatDate(first.WeekStart),
+		fracAsPercent(last.Confidence.Votes, last.Confidence.Possible),
+		formatDate(last.WeekStart),
+	)
+
+	var b strings.Builder
+	fmt.Fprintf(
+		&b,
+		`<svg class="sparkline" role="img" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		sparklineWidth, sparklineHeight, sparklineWidth, sparklineHeight,
+	)
+	fmt.Fprintf(&b, `<title>%s</title>`, template.HTMLEscapeString(label))
+	fmt.Fprintf(&b, `<polyline class="sparkline__line" points="%s" />`, strings.Join(points, " "))
+	fmt.Fprintf(&b, `<circle class="sparkline__point" cx="%.1f" cy="%.1f" r="3" />`, lastX, lastY)
+	b.WriteString(`</svg>`)
+
+	return template.HTML(b.String())
+}
@@ -0,0 +1,273 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+// weakestTaskCount is the number of tasks listed as weakest for each ACS a student is studying.
+const weakestTaskCount = 3
+
+// studentOverview summarizes a student's progress for their instructor's dashboard.
+type studentOverview struct {
+	Student models.Student
+	ACS     []studentACSOverview
+}
+
+// studentACSOverview is a student's progress in one of the ACS documents they are studying.
+type studentACSOverview struct {
+	ACS          models.ACS
+	Areas        []models.AreaOfOperation
+	WeakestTasks []models.TaskSummary
+}
+
+func (a *App) studentDashboard(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	students, err := a.userModel.ListStudents(r.Context(), user.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to list students.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.Students = make([]studentOverview, len(students))
+	for i, student := range students {
+		overview, err := a.buildStudentOverview(r.Context(), student)
+		if err != nil {
+			a.logger.ErrorContext(r.Context(), "Failed to summarize student progress.", "error", err, "studentID", student.ID)
+			a.serverError(w, r, err)
+			return
+		}
+
+		data.Students[i] = overview
+	}
+
+	a.render(w, r, http.StatusOK, "students.html.tmpl", data)
+}
+
+func (a *App) buildStudentOverview(ctx context.Context, student models.Student) (studentOverview, error) {
+	overview := studentOverview{Student: student}
+
+	documents, err := a.acsModel.ListACS(ctx, student.ID)
+	if err != nil {
+		return studentOverview{}, err
+	}
+
+	for _, acs := range documents {
+		if !acs.Studying {
+			continue
+		}
+
+		areas, err := a.acsModel.ListAreasByACS(ctx, student.ID, acs.ID)
+		if err != nil {
+			return studentOverview{}, err
+		}
+
+		weakest, err := a.acsModel.ListWeakestTasks(ctx, student.ID, acs.ID, weakestTaskCount)
+		if err != nil {
+			return studentOverview{}, err
+		}
+
+		overview.ACS = append(overview.ACS, studentACSOverview{ACS: acs, Areas: areas, WeakestTasks: weakest})
+	}
+
+	return overview, nil
+}
+
+// requestStudent loads the student in the request path. It renders a 404 page and returns false if
+// the user isn't one of the current user's students.
+func (a *App) requestStudent(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	user, _ := a.currentUser(r)
+
+	studentID, err := strconv.ParseInt(r.PathValue("studentID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return models.User{}, false
+	}
+
+	student, err := a.userModel.GetStudent(r.Context(), user.ID, int32(studentID))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return models.User{}, false
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve student.", "error", err, "studentID", studentID)
+		a.serverError(w, r, err)
+		return models.User{}, false
+	}
+
+	return student, true
+}
+
+// studentArea is a read-only version of an area's page showing a student's confidence.
+func (a *App) studentArea(w http.ResponseWriter, r *http.Request) {
+	student, ok := a.requestStudent(w, r)
+	if !ok {
+		return
+	}
+
+	acs := r.PathValue("acs")
+	areaID := r.PathValue("areaID")
+
+	area, err := a.acsModel.GetAreaByID(r.Context(), acs, areaID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve ACS area.", "error", err)
+		a.serverError(w, r, err)
+		return
+	}
+
+	tasks, err := a.acsModel.ListTasksByArea(r.Context(), student.ID, area.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to list tasks for area.", "error", err, "acs", acs, "area", area.PublicID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	history, err := a.acsModel.GetAreaConfidenceHistory(r.Context(), student.ID, area.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve area confidence history.", "error", err, "acs", acs, "area", area.PublicID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.Student = student
+	data.AreaOfOperation = area
+	data.Tasks = tasks
+	data.ConfidenceHistory = history
+
+	a.render(w, r, http.StatusOK, "student-area.html.tmpl", data)
+}
+
+// studentTask is a read-only version of a task's page showing a student's confidence. The
+// student's notes are private, so they aren't shown.
+func (a *App) studentTask(w http.ResponseWriter, r *http.Request) {
+	student, ok := a.requestStudent(w, r)
+	if !ok {
+		return
+	}
+
+	acs := r.PathValue("acs")
+	areaID := r.PathValue("areaID")
+	taskID := r.PathValue("taskID")
+
+	task, err := a.acsModel.GetTaskByArea(r.Context(), student.ID, acs, areaID, taskID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(
+			r.Context(),
+			"Failed to retrieve task.",
+			"error", err,
+			"taskPublicID", fmt.Sprintf("%s.%s.%s", acs, areaID, taskID),
+		)
+		a.serverError(w, r, err)
+		return
+	}
+
+	confidence, err := a.acsModel.GetTaskConfidence(r.Context(), student.ID, task.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve task confidence.", "error", err, "taskID", task.ID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	history, err := a.acsModel.GetTaskConfidenceHistory(r.Context(), student.ID, task.ID)
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve task confidence history.", "error", err, "taskID", task.ID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.Student = student
+	data.Task = task
+	data.TaskConfidence = confidence
+	data.ConfidenceHistory = history
+
+	a.render(w, r, http.StatusOK, "student-task.html.tmpl", data)
+}
+
+// signOffElement records that the student in the path demonstrated an element satisfactorily.
+func (a *App) signOffElement(w http.ResponseWriter, r *http.Request) {
+	a.updateElementSignOff(w, r, func(ctx context.Context, instructorID, studentID, elementID int32) error {
+		return a.acsModel.SignOffElement(ctx, instructorID, studentID, elementID)
+	})
+}
+
+// removeElementSignOff removes the sign-off of an element for the student in the path.
+func (a *App) removeElementSignOff(w http.ResponseWriter, r *http.Request) {
+	a.updateElementSignOff(w, r, func(ctx context.Context, _, studentID, elementID int32) error {
+		return a.acsModel.RemoveElementSignOff(ctx, studentID, elementID)
+	})
+}
+
+func (a *App) updateElementSignOff(
+	w http.ResponseWriter,
+	r *http.Request,
+	update func(ctx context.Context, instructorID, studentID, elementID int32) error,
+) {
+	user, _ := a.currentUser(r)
+
+	student, ok := a.requestStudent(w, r)
+	if !ok {
+		return
+	}
+
+	elementID, err := strconv.ParseInt(r.PathValue("elementID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return
+	}
+
+	task, err := a.acsModel.GetTaskByElementID(r.Context(), student.ID, int32(elementID))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to look up element.", "error", err, "elementID", elementID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	elementPublicID, err := a.acsModel.GetElementPublicIDByID(r.Context(), int32(elementID))
+	if err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to look up element.", "error", err, "elementID", elementID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	if err := update(r.Context(), user.ID, student.ID, int32(elementID)); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to update element sign-off.", "error", err, "elementID", elementID, "studentID", student.ID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	redirectTarget := fmt.Sprintf(
+		"/students/%d/acs/%s/%s/%s#%s",
+		student.ID,
+		task.Area.ACS,
+		task.Area.PublicID,
+		task.PublicID,
+		elementPublicID,
+	)
+	http.Redirect(w, r, redirectTarget, http.StatusSeeOther)
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+const (
+	defaultStudySessionSize = 10
+	maxStudySessionSize     = 50
+)
+
+func (a *App) startStudySession(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	acsID := r.PostForm.Get("acs")
+	areaID := r.PostForm.Get("area")
+
+	count := defaultStudySessionSize
+	if raw := r.PostForm.Get("count"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxStudySessionSize {
+			a.genericError(w, r, http.StatusBadRequest)
+			return
+		}
+
+		count = parsed
+	}
+
+	sessionID, err := a.acsModel.StartStudySession(r.Context(), user.ID, acsID, areaID, count)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		if errors.Is(err, models.ErrNothingToStudy) {
+			a.genericError(w, r, http.StatusUnprocessableEntity)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to start study session.", "error", err, "acs", acsID, "area", areaID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/study/%d", sessionID), http.StatusSeeOther)
+}
+
+// studySession shows the next element to rate, or a summary of the session once every element is
+// rated.
+func (a *App) studySession(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	sessionID, err := strconv.ParseInt(r.PathValue("sessionID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return
+	}
+
+	session, err := a.acsModel.GetStudySession(r.Context(), user.ID, int32(sessionID))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to retrieve study session.", "error", err, "sessionID", sessionID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	data := a.newTemplateData(r)
+	data.StudySession = session
+
+	if session.Current() != nil {
+		a.render(w, r, http.StatusOK, "study.html.tmpl", data)
+	} else {
+		a.render(w, r, http.StatusOK, "study-summary.html.tmpl", data)
+	}
+}
+
+func (a *App) rateStudyElement(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	sessionID, err := strconv.ParseInt(r.PathValue("sessionID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return
+	}
+
+	elementID, err := strconv.ParseInt(r.PathValue("elementID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		a.logger.ErrorContext(r.Context(), "Failed to parse form.", "error", err)
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	confidence, err := getConfidenceFromForm(r.PostForm)
+	if err != nil {
+		a.genericError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = a.acsModel.RateStudyElement(r.Context(), user.ID, int32(sessionID), int32(elementID), confidence)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(
+			r.Context(),
+			"Failed to rate study session element.",
+			"error", err,
+			"sessionID", sessionID,
+			"elementID", elementID,
+		)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/study/%d", sessionID), http.StatusSeeOther)
+}
+
+func (a *App) endStudySession(w http.ResponseWriter, r *http.Request) {
+	user, _ := a.currentUser(r)
+	sessionID, err := strconv.ParseInt(r.PathValue("sessionID"), 10, 32)
+	if err != nil {
+		a.notFound(w, r)
+		return
+	}
+
+	if err := a.acsModel.EndStudySession(r.Context(), user.ID, int32(sessionID)); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			a.notFound(w, r)
+			return
+		}
+
+		a.logger.ErrorContext(r.Context(), "Failed to end study session.", "error", err, "sessionID", sessionID)
+		a.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/study/%d", sessionID), http.StatusSeeOther)
+}
@@ -11,17 +11,70 @@ import (
 	"net/http"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/cdriehuys/flight-school/internal/models"
 )
 
 type templateData struct {
+	CurrentUser     models.User
+	IsAuthenticated bool
+
+	Form any
+
+	ACS         models.ACS
+	StudyingACS []models.ACS
+	OtherACS    []models.ACS
+
+	// Editions lists every edition of the ACS being viewed. EditionChanges holds the changes from
+	// CompareACS, an earlier edition, to the ACS being viewed.
+	Editions       []models.ACS
+	CompareACS     models.ACS
+	EditionChanges models.ACSDiff
+
 	AreaOfOperation  models.AreaOfOperation
 	AreasOfOperation []models.AreaOfOperation
 	Task             models.Task
 	TaskConfidence   models.Confidence
 	Tasks            []models.TaskSummary
+
+	ConfidenceHistory []models.ConfidenceSnapshot
+
+	Reviews []models.ReviewItem
+
+	StudySession models.StudySession
+	PracticeTest models.PracticeTest
+
+	ACSOptions []models.ACS
+	Exams      []models.ExamSummary
+	Exam       models.Exam
+
+	SearchResults []models.SearchResult
+
+	// Students summarizes the progress of an instructor's students. Student is set when an
+	// instructor views one student's progress.
+	Students []studentOverview
+	Student  models.User
+
+	// ACSDocument is the editable form of an ACS. DocumentArea and DocumentTask are set when editing
+	// a single area or task within it.
+	ACSDocument  models.ExternalACS
+	DocumentArea *models.ExternalArea
+	DocumentTask *models.ExternalTask
+
+	Error errorPage
+}
+
+// newTemplateData builds the data shared by every page.
+func (app *App) newTemplateData(r *http.Request) templateData {
+	user, ok := app.currentUser(r)
+
+	return templateData{
+		CurrentUser:     user,
+		IsAuthenticated: ok,
+	}
 }
 
 // render executes a template and writes it as the response.
@@ -133,11 +186,24 @@ func (l liveTemplateLoader) Render(w io.Writer, name string, data templateData)
 // set of functionality.
 func templateFuncs(custom template.FuncMap) template.FuncMap {
 	funcs := template.FuncMap{
-		"add":                add,
-		"confidenceButton":   confidenceButton,
-		"confidenceFormData": makeConfidenceFormData,
-		"fracAsPercent":      fracAsPercent,
-		"join":               strings.Join,
+		"add":                    add,
+		"adminElementGroups":     adminElementGroups,
+		"confidenceButton":       confidenceButton,
+		"confidenceFormData":     makeConfidenceFormData,
+		"confidenceName":         confidenceName,
+		"date":                   formatDate,
+		"elementNoteFormData":    makeElementNoteFormData,
+		"examElementListData":    makeExamElementListData,
+		"fracAsPercent":          fracAsPercent,
+		"join":                   strings.Join,
+		"markdown":               markdown,
+		"sparkline":              sparkline,
+		"studentElementListData": makeStudentElementListData,
+		"studyFormData":          makeStudyFormData,
+		"subElementNoteFormData": makeSubElementNoteFormData,
+		"studyingFormData":       makeStudyingFormData,
+		"taskClassRatingOptions": makeTaskClassRatingOptions,
+		"userClassRatingOptions": makeUserClassRatingOptions,
 	}
 
 	for k, f := range custom {
@@ -164,6 +230,35 @@ func fracAsPercent(numerator int, denominator int) int {
 	return int(math.Round(decimal))
 }
 
+// formatDate renders a timestamp as a human readable date.
+func formatDate(t time.Time) string {
+	return t.Local().Format("Jan 2, 2006")
+}
+
+// classRatingOption is a class rating in a form, marked if it is currently chosen.
+type classRatingOption struct {
+	Rating   models.ClassRating
+	Selected bool
+}
+
+func makeTaskClassRatingOptions(appliesTo []string) []classRatingOption {
+	options := make([]classRatingOption, len(models.ClassRatings))
+	for i, rating := range models.ClassRatings {
+		options[i] = classRatingOption{rating, slices.Contains(appliesTo, string(rating))}
+	}
+
+	return options
+}
+
+func makeUserClassRatingOptions(current *models.ClassRating) []classRatingOption {
+	options := make([]classRatingOption, len(models.ClassRatings))
+	for i, rating := range models.ClassRatings {
+		options[i] = classRatingOption{rating, current != nil && *current == rating}
+	}
+
+	return options
+}
+
 type confidenceFormData struct {
 	ElementID       int32
 	ConfidenceLevel *models.ConfidenceLevel
@@ -173,6 +268,83 @@ func makeConfidenceFormData(elementID int32, level *models.ConfidenceLevel) conf
 	return confidenceFormData{elementID, level}
 }
 
+type studyingFormData struct {
+	ACSID    string
+	Studying bool
+	Next     string
+}
+
+func makeStudyingFormData(acsID string, studying bool, next string) studyingFormData {
+	return studyingFormData{acsID, studying, next}
+}
+
+type examElementListData struct {
+	Exam       models.Exam
+	IsExaminer bool
+	Elements   []models.TaskElement
+}
+
+func makeExamElementListData(exam models.Exam, isExaminer bool, elements []models.TaskElement) examElementListData {
+	return examElementListData{exam, isExaminer, elements}
+}
+
+type studentElementListData struct {
+	StudentID int32
+	Elements  []models.TaskElement
+}
+
+func makeStudentElementListData(studentID int32, elements []models.TaskElement) studentElementListData {
+	return studentElementListData{studentID, elements}
+}
+
+type noteFormData struct {
+	ElementID int32
+
+	// SubElement is the position of the sub-element the note is for. It is nil for notes on the
+	// element itself.
+	SubElement *int32
+
+	Note *models.Note
+}
+
+func makeElementNoteFormData(elementID int32, note *models.Note) noteFormData {
+	return noteFormData{ElementID: elementID, Note: note}
+}
+
+func makeSubElementNoteFormData(elementID int32, order int32, note *models.Note) noteFormData {
+	return noteFormData{ElementID: elementID, SubElement: &order, Note: note}
+}
+
+// studyFormData scopes the study session and practice test forms to an ACS or one of its areas.
+type studyFormData struct {
+	ACSID  string
+	AreaID string
+}
+
+func makeStudyFormData(acsID string, areaID string) studyFormData {
+	return studyFormData{acsID, areaID}
+}
+
+// confidenceName describes a confidence level in words.
+func confidenceName(level *models.ConfidenceLevel) string {
+	if level == nil {
+		return "Unrated"
+	}
+
+	switch *level {
+	case models.ConfidenceLevelLow:
+		return "Low"
+
+	case models.ConfidenceLevelMedium:
+		return "Medium"
+
+	case models.ConfidenceLevelHigh:
+		return "High"
+	}
+
+	return "Unknown"
+}
+
 func confidenceButton(rawLevel int32, current *models.ConfidenceLevel) template.HTML {
 	level := models.ConfidenceLevel(rawLevel)
 	classes := []string{"button-group__btn"}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newAPITokenCmd(logStream io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "api-token",
+		Short: "Manage tokens for the JSON API",
+		Long: `Manage tokens for the JSON API.
+
+Requests to /api/v1 authenticate with an "Authorization: Bearer <token>" header.
+Tokens act as the user they were created for.`,
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create username",
+		Short: "Create a token and print it",
+		Long: `Create a token and print it.
+
+The token is only shown once. Only a hash of it is stored.`,
+		Args: cobra.ExactArgs(1),
+		RunE: withUserModel(logStream, createAPITokenRunner),
+	}
+
+	createCmd.Flags().String("name", "default", "Description of where the token is used")
+
+	cmd.AddCommand(
+		createCmd,
+		&cobra.Command{
+			Use:   "list username",
+			Short: "List a user's tokens",
+			Args:  cobra.ExactArgs(1),
+			RunE:  withUserModel(logStream, listAPITokensRunner),
+		},
+		&cobra.Command{
+			Use:   "revoke username token-id",
+			Short: "Revoke one of a user's tokens",
+			Args:  cobra.ExactArgs(2),
+			RunE:  withUserModel(logStream, revokeAPITokenRunner),
+		},
+	)
+
+	return cmd
+}
+
+// withUserModel opens a database connection and resolves the user named by the first argument
+// before running a command.
+func withUserModel(
+	logStream io.Writer,
+	run func(*cobra.Command, []string, *models.UserModel, models.User) error,
+) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		model := models.NewUserModel(logger, db)
+
+		user, err := model.GetByUsername(c.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		return run(c, args, model, user)
+	}
+}
+
+func createAPITokenRunner(c *cobra.Command, _ []string, model *models.UserModel, user models.User) error {
+	name, err := c.Flags().GetString("name")
+	if err != nil {
+		return err
+	}
+
+	token, err := model.CreateAPIToken(c.Context(), user.ID, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.OutOrStdout(), token)
+
+	return nil
+}
+
+func listAPITokensRunner(c *cobra.Command, _ []string, model *models.UserModel, user models.User) error {
+	tokens, err := model.ListAPITokens(c.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) == 0 {
+		fmt.Fprintf(c.OutOrStdout(), "%s has no API tokens.\n", user.Username)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(c.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCREATED\tLAST USED")
+	for _, t := range tokens {
+		lastUsed := "never"
+		if t.LastUsedAt != nil {
+			lastUsed = t.LastUsedAt.Local().Format(time.DateTime)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", t.ID, t.Name, t.CreatedAt.Local().Format(time.DateTime), lastUsed)
+	}
+
+	return w.Flush()
+}
+
+func revokeAPITokenRunner(c *cobra.Command, args []string, model *models.UserModel, user models.User) error {
+	tokenID, err := strconv.ParseInt(args[1], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid token ID %q: %v", args[1], err)
+	}
+
+	if err := model.DeleteAPIToken(c.Context(), user.ID, int32(tokenID)); err != nil {
+		return fmt.Errorf("failed to revoke token %d for %s: %v", tokenID, user.Username, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newAssignStudentCmd(logStream io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "assign-student instructor student",
+		Short: "Let an instructor view a student's progress",
+		Args:  cobra.ExactArgs(2),
+		RunE:  assignStudentRunner(logStream),
+	}
+
+	cmd.Flags().Bool("unassign", false, "Remove the student from the instructor instead")
+
+	return cmd
+}
+
+func assignStudentRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+
+		unassign, err := c.Flags().GetBool("unassign")
+		if err != nil {
+			return err
+		}
+
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		model := models.NewUserModel(logger, db)
+		if unassign {
+			return model.UnassignStudent(c.Context(), args[0], args[1])
+		}
+
+		return model.AssignStudent(c.Context(), args[0], args[1])
+	}
+}
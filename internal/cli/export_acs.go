@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newExportACSCmd(logStream io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-acs acs-id [output-file]",
+		Short: "Write the stored copy of an ACS as a JSON document",
+		Long: `Write the stored copy of an ACS as a JSON document.
+
+The document is rebuilt from the database in the format read by populate-acs,
+with areas, tasks, references, and elements in a stable order. Exporting the
+same content always produces the same bytes, so the output can be diffed
+against a document in the acs directory or loaded again. The document is
+written to standard output unless a file is given.
+
+With --check, nothing is written. Instead the stored ACS is compared to the
+given document, any differences are listed as populate-acs would report them,
+and the command fails if the two don't match.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: exportACSRunner(logStream),
+	}
+
+	cmd.Flags().String("check", "", "Compare the stored ACS to this document instead of writing it")
+
+	return cmd
+}
+
+func exportACSRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+
+		checkFileName, err := c.Flags().GetString("check")
+		if err != nil {
+			return err
+		}
+
+		if checkFileName != "" && len(args) > 1 {
+			return fmt.Errorf("an output file can't be used with --check")
+		}
+
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		model := models.NewACSModel(logger, db)
+
+		acsID := args[0]
+		stored, err := model.ExportACS(c.Context(), acsID)
+		if err != nil {
+			return fmt.Errorf("failed to export ACS: %v", err)
+		}
+
+		if err := models.ValidateACS(stored); err != nil {
+			return fmt.Errorf("stored copy of %s is not a valid document: %v", acsID, err)
+		}
+
+		if checkFileName != "" {
+			return checkStoredACS(c.OutOrStdout(), checkFileName, stored)
+		}
+
+		if len(args) == 1 {
+			return models.EncodeACS(c.OutOrStdout(), stored)
+		}
+
+		outputFileName := args[1]
+		outputFile, err := os.Create(outputFileName)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %v", outputFileName, err)
+		}
+
+		if err := models.EncodeACS(outputFile, stored); err != nil {
+			outputFile.Close()
+			return err
+		}
+
+		if err := outputFile.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %v", outputFileName, err)
+		}
+
+		logger.Info("Exported ACS.", "acs", stored.EditionID(), "file", outputFileName)
+
+		return nil
+	}
+}
+
+// checkStoredACS reports how the stored copy of an ACS differs from a document. The changes are
+// listed as the ones loading the document would make.
+func checkStoredACS(w io.Writer, fileName string, stored models.ExternalACS) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", fileName, err)
+	}
+
+	defer file.Close()
+
+	doc, err := models.DecodeACS(file)
+	if err != nil {
+		return err
+	}
+
+	if doc.EditionID() != stored.EditionID() {
+		return fmt.Errorf("%s describes %s, not %s", fileName, doc.EditionID(), stored.EditionID())
+	}
+
+	diff := models.DiffACS(stored, doc)
+	if diff.Empty() {
+		fmt.Fprintf(w, "%s matches %s\n", stored.EditionID(), fileName)
+		return nil
+	}
+
+	fmt.Fprintf(
+		w,
+		"%s differs from %s: %d added, %d changed, %d removed\n\n",
+		stored.EditionID(),
+		fileName,
+		diff.Count(models.ACSChangeAdded),
+		diff.Count(models.ACSChangeChanged),
+		diff.Count(models.ACSChangeRemoved),
+	)
+
+	if err := writeACSDiffText(w, diff); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("stored copy of %s has drifted from %s", stored.EditionID(), fileName)
+}
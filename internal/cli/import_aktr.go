@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newImportAKTRCmd(logStream io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-aktr username [report-file]",
+		Short: "Flag the elements missed on a knowledge test",
+		Long: `Flag the elements missed on a knowledge test.
+
+The Airman Knowledge Test Report lists the ACS codes, such as PA.I.B.K3, for
+each question answered incorrectly. Those codes are read from the report file,
+or from standard input if no file is given, and the matching elements are
+flagged as missed on the written test. Any other text in the report is ignored.
+
+Codes that don't match an element in a loaded ACS are listed but don't cause
+the import to fail.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: importAKTRRunner(logStream),
+	}
+
+	cmd.Flags().Bool("low-confidence", false, "Also record a low confidence vote for each missed element")
+
+	return cmd
+}
+
+func importAKTRRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		lowConfidence, err := c.Flags().GetBool("low-confidence")
+		if err != nil {
+			return err
+		}
+
+		input := c.InOrStdin()
+		if len(args) == 2 {
+			file, err := os.Open(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %v", args[1], err)
+			}
+
+			defer file.Close()
+
+			input = file
+		}
+
+		report, err := io.ReadAll(input)
+		if err != nil {
+			return fmt.Errorf("failed to read knowledge test report: %v", err)
+		}
+
+		codes := models.ParseACSCodes(string(report))
+		if len(codes) == 0 {
+			return fmt.Errorf("no ACS codes found in the knowledge test report")
+		}
+
+		user, err := models.NewUserModel(logger, db).GetByUsername(c.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		result, err := models.NewACSModel(logger, db).ImportKnowledgeTest(
+			c.Context(),
+			user.ID,
+			codes,
+			models.KnowledgeTestImportOptions{SetLowConfidence: lowConfidence},
+		)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.OutOrStdout(), "Flagged %d missed elements.\n", len(result.Matched))
+		if len(result.Unknown) > 0 {
+			fmt.Fprintf(
+				c.OutOrStdout(),
+				"Codes not found in any loaded ACS: %s\n",
+				strings.Join(result.Unknown, ", "),
+			)
+		}
+
+		return nil
+	}
+}
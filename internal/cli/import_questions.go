@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newImportQuestionsCmd(logStream io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-questions [file]",
+		Short: "Import knowledge test questions for practice tests",
+		Long: `Import knowledge test questions for practice tests.
+
+Questions are multiple choice and are tagged with the codes of the elements
+they test, such as PA.I.B.K3. They are read from the given file, or from
+standard input if no file is given. Files ending in .csv are read as CSV and
+anything else as JSON, unless --format is given.
+
+Importing a question with the same ID as an existing one replaces it. Element
+codes that don't match an element in a loaded ACS are listed but don't cause
+the import to fail.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: importQuestionsRunner(logStream),
+	}
+
+	cmd.Flags().String("format", "", "Format of the question file, either json or csv")
+
+	return cmd
+}
+
+func importQuestionsRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		format, err := c.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		input := c.InOrStdin()
+		if len(args) == 1 {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %v", args[0], err)
+			}
+
+			defer file.Close()
+
+			input = file
+
+			if format == "" && strings.EqualFold(filepath.Ext(args[0]), ".csv") {
+				format = "csv"
+			}
+		}
+
+		var bank models.QuestionBank
+		switch format {
+		case "", "json":
+			if err := json.NewDecoder(input).Decode(&bank); err != nil {
+				return fmt.Errorf("failed to read questions: %v", err)
+			}
+		case "csv":
+			bank, err = models.ParseQuestionCSV(input)
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown format %q, expected json or csv", format)
+		}
+
+		logger := createLogger(logStream)
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		result, err := models.NewACSModel(logger, db).ImportQuestions(c.Context(), bank)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.OutOrStdout(), "Imported %d questions.\n", result.Questions)
+		if len(result.Unknown) > 0 {
+			fmt.Fprintf(
+				c.OutOrStdout(),
+				"Codes not found in any loaded ACS: %s\n",
+				strings.Join(result.Unknown, ", "),
+			)
+		}
+
+		return nil
+	}
+}
@@ -119,11 +119,19 @@ func loadACSDefinition(ctx context.Context, logger *slog.Logger, model acsUpdate
 		}
 	}()
 
-	if err := populateACSFromJSON(ctx, model, file); err != nil {
+	// Embedded documents are loaded without any remapping, so they refuse to remove rated
+	// elements. Use populate-acs directly to remap or discard that data.
+	report, err := populateACSFromJSON(ctx, model, file, models.PopulateOptions{})
+	if err != nil {
 		return err
 	}
 
-	logger.InfoContext(ctx, "Populated ACS definition", "document", name)
+	logger.InfoContext(
+		ctx,
+		"Populated ACS definition",
+		"document", name,
+		"changes", len(report.Diff.Changes),
+	)
 
 	return nil
 }
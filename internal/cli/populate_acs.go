@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"text/tabwriter"
 
 	"github.com/cdriehuys/flight-school/internal/models"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,12 +16,38 @@ import (
 )
 
 func newPopulateACSCmd(logStream io.Writer) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "populate-acs definition-file",
 		Short: "Populate the database with a particular ACS",
-		Args:  cobra.ExactArgs(1),
-		RunE:  populateACSRunner(logStream),
+		Long: `Populate the database with a particular ACS.
+
+Loading a document that no longer contains elements users have rated fails
+unless the ratings are moved with --remap or discarded with --allow-data-loss.
+Remapping onto an element replaces its own ratings unless they are remapped
+too, so that also requires --allow-data-loss.
+
+The remap file is a JSON object mapping old public IDs to new ones. Entries may
+be elements, such as "PA.I.A.K3": "PA.I.A.K4", or entire tasks, such as
+"PA.IX.B": "PA.X.C", which moves each element to the same element in the new
+task.
+
+Use --dry-run to see what a document would change without saving anything. The
+changes are printed as text, or as JSON with --format=json.
+
+Documents with an "edition" are stored alongside the other editions of the same
+ACS. The first edition loaded becomes the current edition; pass --current to
+make the loaded edition current instead.`,
+		Args: cobra.ExactArgs(1),
+		RunE: populateACSRunner(logStream),
 	}
+
+	cmd.Flags().String("remap", "", "JSON file mapping old element or task IDs to new ones")
+	cmd.Flags().Bool("allow-data-loss", false, "Remove elements even if users have rated them")
+	cmd.Flags().Bool("dry-run", false, "Report the changes the document would make, then roll them back")
+	cmd.Flags().String("format", "text", "Output format for the change report: text or json")
+	cmd.Flags().Bool("current", false, "Make the loaded edition the current edition of its ACS")
+
+	return cmd
 }
 
 func populateACSRunner(logStream io.Writer) func(*cobra.Command, []string) error {
@@ -31,8 +58,29 @@ func populateACSRunner(logStream io.Writer) func(*cobra.Command, []string) error
 			return fmt.Errorf("failed to open database connection: %v", err)
 		}
 
+		defer db.Close()
+
 		model := models.NewACSModel(logger, db)
 
+		opts, err := populateOptionsFromFlags(c)
+		if err != nil {
+			return err
+		}
+
+		format, err := c.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format %q; expected text or json", format)
+		}
+
+		current, err := c.Flags().GetBool("current")
+		if err != nil {
+			return err
+		}
+
 		acsFileName := args[0]
 		acsFile, err := os.Open(acsFileName)
 		if err != nil {
@@ -41,33 +89,215 @@ func populateACSRunner(logStream io.Writer) func(*cobra.Command, []string) error
 
 		logger.Info("Opened ACS document", "file", acsFileName)
 
-		if err := populateACSFromJSON(c.Context(), model, acsFile); err != nil {
-			return fmt.Errorf("failed to populate ACS: %v", err)
-		}
-
 		defer func() {
 			if err := acsFile.Close(); err != nil {
 				log.Printf("Error closing ACS file: %v", err)
 			}
 		}()
 
-		return nil
+		report, err := populateACSFromJSON(c.Context(), model, acsFile, opts)
+		if err != nil {
+			return fmt.Errorf("failed to populate ACS: %v", err)
+		}
+
+		if current && !report.DryRun {
+			if err := model.SetCurrentEdition(c.Context(), report.ACS); err != nil {
+				return fmt.Errorf("failed to make %s current: %v", report.ACS, err)
+			}
+		}
+
+		if format == "json" {
+			return writePopulateReportJSON(c.OutOrStdout(), report)
+		}
+
+		return writePopulateReportText(c.OutOrStdout(), report)
+	}
+}
+
+func populateOptionsFromFlags(c *cobra.Command) (models.PopulateOptions, error) {
+	opts := models.PopulateOptions{}
+
+	allowDataLoss, err := c.Flags().GetBool("allow-data-loss")
+	if err != nil {
+		return models.PopulateOptions{}, err
+	}
+
+	opts.AllowDataLoss = allowDataLoss
+
+	dryRun, err := c.Flags().GetBool("dry-run")
+	if err != nil {
+		return models.PopulateOptions{}, err
+	}
+
+	opts.DryRun = dryRun
+
+	remapFileName, err := c.Flags().GetString("remap")
+	if err != nil {
+		return models.PopulateOptions{}, err
+	}
+
+	if remapFileName != "" {
+		remapFile, err := os.Open(remapFileName)
+		if err != nil {
+			return models.PopulateOptions{}, fmt.Errorf("failed to open %s: %v", remapFileName, err)
+		}
+
+		defer remapFile.Close()
+
+		if err := json.NewDecoder(remapFile).Decode(&opts.Remap); err != nil {
+			return models.PopulateOptions{}, fmt.Errorf("failed to decode remap file %s: %v", remapFileName, err)
+		}
 	}
+
+	return opts, nil
 }
 
 type acsUpdater interface {
-	PopulateACS(ctx context.Context, acs models.ExternalACS) error
+	PopulateACS(ctx context.Context, acs models.ExternalACS, opts models.PopulateOptions) (models.PopulateReport, error)
 }
 
-func populateACSFromJSON(ctx context.Context, model acsUpdater, input io.Reader) error {
-	var acs models.ExternalACS
-	if err := json.NewDecoder(input).Decode(&acs); err != nil {
-		return fmt.Errorf("failed to decode JSON ACS: %v", err)
+func populateACSFromJSON(
+	ctx context.Context,
+	model acsUpdater,
+	input io.Reader,
+	opts models.PopulateOptions,
+) (models.PopulateReport, error) {
+	acs, err := models.DecodeACS(input)
+	if err != nil {
+		return models.PopulateReport{}, err
+	}
+
+	report, err := model.PopulateACS(ctx, acs, opts)
+	if err != nil {
+		return models.PopulateReport{}, fmt.Errorf("failed to update ACS: %v", err)
+	}
+
+	return report, nil
+}
+
+func writePopulateReportJSON(w io.Writer, report models.PopulateReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(struct {
+		models.PopulateReport
+		LostVotes int `json:"lostVotes"`
+	}{report, report.LostVotes()})
+}
+
+// changeSymbols prefixes each line of the text report, similar to a unified diff.
+var changeSymbols = map[models.ACSChangeKind]string{
+	models.ACSChangeAdded:   "+",
+	models.ACSChangeChanged: "~",
+	models.ACSChangeRemoved: "-",
+}
+
+func writePopulateReportText(w io.Writer, report models.PopulateReport) error {
+	verb := "Populated"
+	if report.DryRun {
+		verb = "Dry run of"
+	}
+
+	fmt.Fprintf(
+		w,
+		"%s %s: %d added, %d changed, %d removed\n",
+		verb,
+		report.ACS,
+		report.Diff.Count(models.ACSChangeAdded),
+		report.Diff.Count(models.ACSChangeChanged),
+		report.Diff.Count(models.ACSChangeRemoved),
+	)
+
+	if !report.Diff.Empty() {
+		fmt.Fprintln(w)
+	}
+
+	if err := writeACSDiffText(w, report.Diff); err != nil {
+		return err
 	}
 
-	if err := model.PopulateACS(ctx, acs); err != nil {
-		return fmt.Errorf("failed to update ACS: %v", err)
+	if len(report.DataLoss) > 0 {
+		fmt.Fprintf(
+			w,
+			"\nUser data lost from %d elements, including %d confidence votes\n",
+			len(report.DataLoss),
+			report.LostVotes(),
+		)
+
+		if err := writeElementUserDataText(w, report.DataLoss); err != nil {
+			return err
+		}
+	}
+
+	if len(report.ChangedElements) > 0 {
+		fmt.Fprintf(
+			w,
+			"\nContent changed on %d elements with user data; remap them if the requirement moved\n",
+			len(report.ChangedElements),
+		)
+
+		if err := writeElementUserDataText(w, report.ChangedElements); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// writeElementUserDataText writes one line per element summarizing its user data.
+func writeElementUserDataText(w io.Writer, elements []models.ElementUserData) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, element := range elements {
+		fmt.Fprintf(
+			tw,
+			"  %s\t%d votes\t%d history events\t%d notes\t%d sign-offs\t%d missed\t%d exam grades\n",
+			element.FullPublicID,
+			element.Votes,
+			element.HistoryEvents,
+			element.Notes,
+			element.SignOffs,
+			element.Missed,
+			element.ExamGrades,
+		)
+	}
+
+	return tw.Flush()
+}
+
+// writeACSDiffText writes one line per change in a diff.
+func writeACSDiffText(w io.Writer, diff models.ACSDiff) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, change := range diff.Changes {
+		switch change.Kind {
+		case models.ACSChangeAdded:
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%q\n", changeSymbols[change.Kind], change.Type, change.ID, truncate(change.After))
+		case models.ACSChangeRemoved:
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%q\n", changeSymbols[change.Kind], change.Type, change.ID, truncate(change.Before))
+		default:
+			fmt.Fprintf(
+				tw,
+				"%s\t%s\t%s\t%s: %q -> %q\n",
+				changeSymbols[change.Kind],
+				change.Type,
+				change.ID,
+				change.Field,
+				truncate(change.Before),
+				truncate(change.After),
+			)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// truncate shortens long content so each change fits on a single line.
+func truncate(s string) string {
+	const maxLength = 60
+
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+
+	return string(runes[:maxLength-3]) + "..."
+}
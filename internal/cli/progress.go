@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newExportProgressCmd(logStream io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export-progress username [file]",
+		Short: "Export a user's progress to a file",
+		Long: `Export a user's progress to a file.
+
+The export includes the ACS documents the user is studying, their confidence
+history, review schedule, missed knowledge test elements, and notes. Elements
+are identified by their ACS code, such as PA.I.B.K3, so the file can be
+imported on a server where the ACS documents were loaded separately.
+
+The progress is written to the given file, or to standard output if no file is
+given.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: exportProgressRunner(logStream),
+	}
+}
+
+func exportProgressRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		user, err := models.NewUserModel(logger, db).GetByUsername(c.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		progress, err := models.NewACSModel(logger, db).ExportProgress(c.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		output := c.OutOrStdout()
+		if len(args) == 2 {
+			file, err := os.Create(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %v", args[1], err)
+			}
+
+			defer file.Close()
+
+			output = file
+		}
+
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(progress); err != nil {
+			return fmt.Errorf("failed to write progress: %v", err)
+		}
+
+		logger.Info("Exported progress.", "username", user.Username, "elements", len(progress.Elements))
+
+		return nil
+	}
+}
+
+func newImportProgressCmd(logStream io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "import-progress username [file]",
+		Short: "Import a user's progress from a file",
+		Long: `Import a user's progress from a file.
+
+The file is read from the given path, or from standard input if no path is
+given. Imported progress is merged with the user's existing progress: votes
+that are already recorded are skipped, and review schedules and notes are only
+replaced by newer copies, so importing the same file twice is harmless.
+
+Elements and ACS documents that aren't loaded on this server are listed but
+don't cause the import to fail.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: importProgressRunner(logStream),
+	}
+}
+
+func importProgressRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		input := c.InOrStdin()
+		if len(args) == 2 {
+			file, err := os.Open(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %v", args[1], err)
+			}
+
+			defer file.Close()
+
+			input = file
+		}
+
+		var progress models.Progress
+		if err := json.NewDecoder(input).Decode(&progress); err != nil {
+			return fmt.Errorf("failed to read progress: %v", err)
+		}
+
+		user, err := models.NewUserModel(logger, db).GetByUsername(c.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		result, err := models.NewACSModel(logger, db).ImportProgress(c.Context(), user.ID, progress)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(
+			c.OutOrStdout(),
+			"Matched %d elements. Added %d confidence votes, %d review schedules, %d missed elements, and %d notes.\n",
+			result.Matched,
+			result.Votes,
+			result.Reviews,
+			result.Missed,
+			result.Notes,
+		)
+		if len(result.Unknown) > 0 {
+			fmt.Fprintf(c.OutOrStdout(), "Not found on this server: %s\n", strings.Join(result.Unknown, ", "))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/cdriehuys/flight-school/internal/report"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newReportCmd(logStream io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report username acs",
+		Short: "Summarize a user's confidence across an ACS",
+		Long: `Summarize a user's confidence across an ACS.
+
+The CSV format has one row per element with its ACS code, type, content, and
+the user's confidence. The Markdown format groups elements by area and task,
+with the weakest areas, tasks, and elements first. The PDF format is a printable
+study guide in document order that includes the user's notes.`,
+		Args: cobra.ExactArgs(2),
+		RunE: reportRunner(logStream),
+	}
+
+	cmd.Flags().String("area", "", "Only include the area of operation with this ID, such as I")
+	cmd.Flags().String("format", "markdown", "Output format, either csv, markdown, or pdf")
+	cmd.Flags().StringP("output", "o", "", "Write the report to this file instead of standard output")
+
+	return cmd
+}
+
+func reportRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+
+		format, err := c.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		var write func(io.Writer, report.Report) error
+		switch format {
+		case "csv":
+			write = report.WriteCSV
+		case "markdown", "md":
+			write = report.WriteMarkdown
+		case "pdf":
+			write = report.WritePDF
+		default:
+			return fmt.Errorf("unknown report format %q", format)
+		}
+
+		areaID, err := c.Flags().GetString("area")
+		if err != nil {
+			return err
+		}
+
+		outputPath, err := c.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		user, err := models.NewUserModel(logger, db).GetByUsername(c.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		acsModel := models.NewACSModel(logger, db)
+
+		var rep report.Report
+		if areaID == "" {
+			rep, err = report.Build(c.Context(), acsModel, user, args[1])
+		} else {
+			rep, err = report.BuildArea(c.Context(), acsModel, user, args[1], areaID)
+		}
+		if err != nil {
+			return err
+		}
+
+		output := c.OutOrStdout()
+		if outputPath != "" {
+			file, err := os.Create(outputPath)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %v", outputPath, err)
+			}
+
+			defer file.Close()
+
+			output = file
+		}
+
+		if err := write(output, rep); err != nil {
+			return fmt.Errorf("failed to write report: %v", err)
+		}
+
+		return nil
+	}
+}
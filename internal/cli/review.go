@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newReviewCmd(logStream io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "review username",
+		Short: "List the elements a user has due for review",
+		Args:  cobra.ExactArgs(1),
+		RunE:  reviewRunner(logStream),
+	}
+
+	cmd.Flags().String("base-url", "", "Prefix links with this URL, e.g. https://flight-school.example.com")
+
+	return cmd
+}
+
+func reviewRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		baseURL, err := c.Flags().GetString("base-url")
+		if err != nil {
+			return err
+		}
+
+		user, err := models.NewUserModel(logger, db).GetByUsername(c.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		reviews, err := models.NewACSModel(logger, db).ListDueReviews(c.Context(), user.ID, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if len(reviews) == 0 {
+			fmt.Fprintln(c.OutOrStdout(), "Nothing is due for review.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(c.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ELEMENT\tDUE\tLINK")
+		for _, r := range reviews {
+			link := strings.TrimSuffix(baseURL, "/") + r.Path()
+			fmt.Fprintf(w, "%s\t%s\t%s\n", r.FullPublicID, r.DueAt.Local().Format(time.DateOnly), link)
+		}
+
+		return w.Flush()
+	}
+}
@@ -41,8 +41,21 @@ func NewRootCmd(logStream io.Writer, acsDocs fs.FS, migrationFS fs.FS) *cobra.Co
 	viper.BindPFlag("template-dir", cmd.Flags().Lookup("template-dir"))
 
 	cmd.AddCommand(
+		newAPITokenCmd(logStream),
+		newAssignStudentCmd(logStream),
+		newExportACSCmd(logStream),
+		newExportProgressCmd(logStream),
+		newImportAKTRCmd(logStream),
+		newImportProgressCmd(logStream),
+		newImportQuestionsCmd(logStream),
 		newMigrateCmd(logStream, acsDocs, migrationFS),
 		newPopulateACSCmd(logStream),
+		newReportCmd(logStream),
+		newReviewCmd(logStream),
+		newSetAdminCmd(logStream),
+		newSetPasswordCmd(logStream),
+		newSetRoleCmd(logStream),
+		newValidateACSCmd(),
 	)
 
 	return cmd
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newSetAdminCmd(logStream io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-admin username",
+		Short: "Allow a user to edit ACS content from the admin pages",
+		Args:  cobra.ExactArgs(1),
+		RunE:  setAdminRunner(logStream),
+	}
+
+	cmd.Flags().Bool("revoke", false, "Remove the user's admin access instead")
+
+	return cmd
+}
+
+func setAdminRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+
+		revoke, err := c.Flags().GetBool("revoke")
+		if err != nil {
+			return err
+		}
+
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		return models.NewUserModel(logger, db).SetAdmin(c.Context(), args[0], !revoke)
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newSetPasswordCmd(logStream io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-password username",
+		Short: "Set a user's password from the first line of standard input",
+		Long: `Set a user's password from the first line of standard input.
+
+This is also used to claim the "initial" account that owns any confidence votes
+recorded before user accounts existed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: setPasswordRunner(logStream),
+	}
+}
+
+func setPasswordRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		scanner := bufio.NewScanner(c.InOrStdin())
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return fmt.Errorf("failed to read password: %v", err)
+			}
+
+			return fmt.Errorf("no password provided on standard input")
+		}
+
+		password := strings.TrimRight(scanner.Text(), "\r")
+		if len(password) < 8 {
+			return fmt.Errorf("passwords must be at least 8 characters long")
+		}
+
+		model := models.NewUserModel(logger, db)
+
+		return model.SetPassword(c.Context(), args[0], password)
+	}
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newSetRoleCmd(logStream io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-role username student|instructor",
+		Short: "Make a user a student or an instructor",
+		Long: `Make a user a student or an instructor.
+
+Instructors can view the progress of the students assigned to them with
+assign-student. Making an instructor a student again removes all of their
+student assignments.`,
+		Args: cobra.ExactArgs(2),
+		RunE: setRoleRunner(logStream),
+	}
+}
+
+func setRoleRunner(logStream io.Writer) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		logger := createLogger(logStream)
+
+		role, err := models.ParseUserRole(args[1])
+		if err != nil {
+			return err
+		}
+
+		db, err := pgxpool.New(c.Context(), viper.GetString("dsn"))
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %v", err)
+		}
+
+		defer db.Close()
+
+		return models.NewUserModel(logger, db).SetRole(c.Context(), args[0], role)
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/spf13/cobra"
+)
+
+func newValidateACSCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate-acs definition-file...",
+		Short: "Check ACS documents for problems without loading them",
+		Long: `Check ACS documents for problems without loading them.
+
+Documents are validated against the ACS schema and checked for duplicate public
+IDs. Every problem is reported with its JSON path and the public ID of the item
+that contains it. The same checks run before populate-acs and migrate load a
+document.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: validateACSRunner,
+	}
+
+	return cmd
+}
+
+func validateACSRunner(c *cobra.Command, args []string) error {
+	invalid := 0
+	for _, fileName := range args {
+		valid, err := validateACSFile(c.OutOrStdout(), fileName)
+		if err != nil {
+			return err
+		}
+
+		if !valid {
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d documents are invalid", invalid, len(args))
+	}
+
+	return nil
+}
+
+func validateACSFile(w io.Writer, fileName string) (bool, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %v", fileName, err)
+	}
+
+	defer file.Close()
+
+	_, err = models.DecodeACS(file)
+	if err == nil {
+		fmt.Fprintf(w, "%s: ok\n", fileName)
+		return true, nil
+	}
+
+	var validationErr *models.ACSValidationError
+	if !errors.As(err, &validationErr) {
+		return false, fmt.Errorf("failed to validate %s: %v", fileName, err)
+	}
+
+	for _, violation := range validationErr.Violations {
+		fmt.Fprintf(w, "%s: %s\n", fileName, violation)
+	}
+
+	return false, nil
+}
@@ -2,13 +2,52 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+type ACS struct {
+	// ID identifies the edition of the ACS. See EditionID.
+	ID string
+
+	// Code is the two letter code shared by every edition of the ACS.
+	Code    string
+	Edition string
+	Name    string
+	Kind    ACSKind
+
+	// Current indicates the edition is the one new students should study.
+	Current bool
+
+	AreaCount  int
+	Confidence Confidence
+
+	// Studying indicates if the user is currently studying for the certificate the ACS covers.
+	Studying bool
+}
+
+func acsFromModel(m queries.ACS) ACS {
+	return ACS{
+		ID:      m.ID,
+		Code:    m.Code,
+		Edition: m.Edition,
+		Name:    m.Name,
+		Kind:    ACSKind(m.Kind),
+		Current: m.IsCurrent,
+	}
+}
+
+// IsPTS indicates the document is one of the Practical Test Standards that preceded the ACS.
+func (a ACS) IsPTS() bool {
+	return a.Kind == ACSKindPTS
+}
+
 type AreaOfOperation struct {
 	ID       int32
 	ACS      string
@@ -17,6 +56,7 @@ type AreaOfOperation struct {
 
 	TaskCount  int
 	Confidence Confidence
+	Readiness  Readiness
 }
 
 func (a AreaOfOperation) FullID() string {
@@ -33,19 +73,26 @@ func areaOfOperationFromModel(m queries.AcsArea) AreaOfOperation {
 }
 
 type TaskSummary struct {
-	ID        int32
-	AreaID    int32
-	PublicID  string
-	Name      string
-	Objective string
+	ID           int32
+	AreaID       int32
+	AreaPublicID string
+	PublicID     string
+	Name         string
+	Objective    string
+
+	// AppliesTo lists the class ratings the task applies to. It is empty if the task applies to
+	// every class.
+	AppliesTo []string
 
 	FullPublicID string
 
 	Confidence Confidence
+	Readiness  Readiness
 
 	KnowledgeElementCount      int
 	RiskManagementElementCount int
 	SkillElementCount          int
+	PTSElementCount            int
 }
 
 type Task struct {
@@ -55,6 +102,10 @@ type Task struct {
 	Objective string
 	Note      string
 
+	// AppliesTo lists the class ratings the task applies to. It is empty if the task applies to
+	// every class.
+	AppliesTo []string
+
 	Area       AreaOfOperation
 	Confidence Confidence
 
@@ -63,6 +114,7 @@ type Task struct {
 	KnowledgeElements      []TaskElement
 	RiskManagementElements []TaskElement
 	SkillElements          []TaskElement
+	PTSElements            []TaskElement
 }
 
 func (t Task) FullPublicID() string {
@@ -75,6 +127,18 @@ const (
 	TaskElementTypeKnowledge      TaskElementType = "K"
 	TaskElementTypeRiskManagement TaskElementType = "R"
 	TaskElementTypeSkills         TaskElementType = "S"
+
+	// TaskElementTypePTS is used for the elements of PTS tasks, which aren't split into
+	// knowledge, risk management, and skills.
+	TaskElementTypePTS TaskElementType = "E"
+)
+
+// ACSKind tells ACS documents apart from the Practical Test Standards they replaced.
+type ACSKind string
+
+const (
+	ACSKindACS ACSKind = "acs"
+	ACSKindPTS ACSKind = "pts"
 )
 
 type TaskElement struct {
@@ -87,6 +151,21 @@ type TaskElement struct {
 	FullPublicID    string
 	ConfidenceLevel *ConfidenceLevel
 
+	// ReviewDueAt is when the element is next due for review. It is nil for unrated elements.
+	ReviewDueAt *time.Time
+
+	// MissedOnWritten indicates the element was listed on the user's knowledge test report.
+	MissedOnWritten bool
+
+	// Note is the user's personal note on the element, if they have written one.
+	Note *Note
+
+	// SignOff is the instructor's sign-off of the element, if one has been given.
+	SignOff *SignOff
+
+	// Questions summarizes the user's answers to practice questions about the element.
+	Questions QuestionStats
+
 	SubElements []SubElement
 }
 
@@ -95,6 +174,8 @@ type SubElement struct {
 	ElementID int32
 	Order     int32
 	Content   string
+
+	Note *Note
 }
 
 func (s SubElement) PublicID() string {
@@ -121,20 +202,116 @@ func NewACSModel(logger *slog.Logger, db *pgxpool.Pool) *ACSModel {
 	return &ACSModel{logger, db, *queries.New(db)}
 }
 
+func (m *ACSModel) ListACS(ctx context.Context, userID int32) ([]ACS, error) {
+	rows, err := m.q.ListACS(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ACS documents: %v", err)
+	}
+
+	documents := make([]ACS, len(rows))
+	for i, row := range rows {
+		acs := acsFromModel(row.ACS)
+		acs.AreaCount = int(row.AreaCount)
+		acs.Confidence = Confidence{Votes: int(row.Votes), Possible: int(row.MaxVotes)}
+		acs.Studying = row.Studying
+
+		documents[i] = acs
+	}
+
+	return documents, nil
+}
+
+func (m *ACSModel) GetACS(ctx context.Context, userID int32, id string) (ACS, error) {
+	row, err := m.q.GetACSByID(ctx, queries.GetACSByIDParams{AcsID: id, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ACS{}, newNotFoundError("ACS", id, err)
+		}
+
+		return ACS{}, fmt.Errorf("failed to retrieve ACS %s: %v", id, err)
+	}
+
+	acs := acsFromModel(row.ACS)
+	acs.AreaCount = int(row.AreaCount)
+	acs.Confidence = Confidence{Votes: int(row.Votes), Possible: int(row.MaxVotes)}
+	acs.Studying = row.Studying
+
+	return acs, nil
+}
+
+// SetStudying records whether or not a user is studying for a particular ACS. Users study one
+// edition of an ACS at a time, so studying an edition stops them from studying the others.
+func (m *ACSModel) SetStudying(ctx context.Context, userID int32, acsID string, studying bool) error {
+	if !studying {
+		if err := m.q.RemoveUserACS(ctx, queries.RemoveUserACSParams{UserID: userID, AcsID: acsID}); err != nil {
+			return fmt.Errorf("failed to mark ACS %s as not studied: %v", acsID, err)
+		}
+
+		m.logger.InfoContext(ctx, "Updated studied ACS.", "userID", userID, "acs", acsID, "studying", studying)
+
+		return nil
+	}
+
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback studied ACS transaction.", "error", err)
+		}
+	}()
+
+	if err := setStudying(ctx, queries.New(tx), userID, acsID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit studied ACS: %v", err)
+	}
+
+	m.logger.InfoContext(ctx, "Updated studied ACS.", "userID", userID, "acs", acsID, "studying", studying)
+
+	return nil
+}
+
+// setStudying marks an edition of an ACS as studied in place of any other edition of it.
+func setStudying(ctx context.Context, q *queries.Queries, userID int32, acsID string) error {
+	if err := q.AddUserACS(ctx, queries.AddUserACSParams{UserID: userID, AcsID: acsID}); err != nil {
+		if isForeignKeyViolation(err) {
+			return newNotFoundError("ACS", acsID, err)
+		}
+
+		return fmt.Errorf("failed to mark ACS %s as studied: %v", acsID, err)
+	}
+
+	err := q.RemoveUserOtherEditions(ctx, queries.RemoveUserOtherEditionsParams{UserID: userID, AcsID: acsID})
+	if err != nil {
+		return fmt.Errorf("failed to stop studying other editions of %s: %v", acsID, err)
+	}
+
+	return nil
+}
+
 func (m *ACSModel) GetAreaByID(ctx context.Context, acs string, id string) (AreaOfOperation, error) {
 	areaModel, err := m.q.GetAreaByPublicID(ctx, queries.GetAreaByPublicIDParams{
 		AcsID:    acs,
 		PublicID: id,
 	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return AreaOfOperation{}, newNotFoundError("area", acs+"."+id, err)
+		}
+
 		return AreaOfOperation{}, fmt.Errorf("failed to retrieve area %s.%s: %v", acs, id, err)
 	}
 
 	return areaOfOperationFromModel(areaModel), nil
 }
 
-func (m *ACSModel) ListAreasByACS(ctx context.Context, acs string) ([]AreaOfOperation, error) {
-	areaModels, err := m.q.ListAreasByACS(ctx, acs)
+func (m *ACSModel) ListAreasByACS(ctx context.Context, userID int32, acs string) ([]AreaOfOperation, error) {
+	areaModels, err := m.q.ListAreasByACS(ctx, queries.ListAreasByACSParams{AcsID: acs, UserID: userID})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list areas for ACS %s: %v", acs, err)
 	}
@@ -144,6 +321,7 @@ func (m *ACSModel) ListAreasByACS(ctx context.Context, acs string) ([]AreaOfOper
 		area := areaOfOperationFromModel(a.AcsArea)
 		area.TaskCount = int(a.TaskCount)
 		area.Confidence = Confidence{Votes: int(a.Votes), Possible: int(a.MaxVotes)}
+		area.Readiness = Readiness{SignedOff: int(a.SignedOffCount), Elements: int(a.ElementCount)}
 
 		areas[i] = area
 	}
@@ -151,8 +329,8 @@ func (m *ACSModel) ListAreasByACS(ctx context.Context, acs string) ([]AreaOfOper
 	return areas, nil
 }
 
-func (m *ACSModel) ListTasksByArea(ctx context.Context, areaID int32) ([]TaskSummary, error) {
-	taskModels, err := m.q.ListTasksByArea(ctx, int32(areaID))
+func (m *ACSModel) ListTasksByArea(ctx context.Context, userID int32, areaID int32) ([]TaskSummary, error) {
+	taskModels, err := m.q.ListTasksByArea(ctx, queries.ListTasksByAreaParams{AreaID: areaID, UserID: userID})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tasks for area %d: %v", areaID, err)
 	}
@@ -162,27 +340,69 @@ func (m *ACSModel) ListTasksByArea(ctx context.Context, areaID int32) ([]TaskSum
 		tasks[i] = TaskSummary{
 			ID:                         t.Task.ID,
 			AreaID:                     areaID,
+			AreaPublicID:               t.AcsArea.PublicID,
 			PublicID:                   t.Task.PublicID,
 			Name:                       t.Task.Name,
 			Objective:                  t.Task.Objective,
+			AppliesTo:                  t.Task.AppliesTo,
 			FullPublicID:               t.FullPublicID,
 			Confidence:                 Confidence{int(t.Votes), int(t.MaxVotes)},
 			KnowledgeElementCount:      int(t.KnowledgeElementCount),
 			RiskManagementElementCount: int(t.RiskElementCount),
 			SkillElementCount:          int(t.SkillElementCount),
+			PTSElementCount:            int(t.PtsElementCount),
+		}
+
+		tasks[i].Readiness = Readiness{
+			SignedOff: int(t.SignedOffCount),
+			Elements:  int(t.KnowledgeElementCount + t.RiskElementCount + t.SkillElementCount + t.PtsElementCount),
 		}
 	}
 
 	return tasks, nil
 }
 
-func (m *ACSModel) GetTaskByArea(ctx context.Context, acs string, areaID string, taskID string) (Task, error) {
+// ListWeakestTasks returns the tasks in an ACS where a user has given the smallest share of the
+// possible confidence votes, starting with the weakest.
+func (m *ACSModel) ListWeakestTasks(ctx context.Context, userID int32, acsID string, count int) ([]TaskSummary, error) {
+	rows, err := m.q.ListWeakestTasks(ctx, queries.ListWeakestTasksParams{
+		UserID: userID,
+		AcsID:  acsID,
+		Count:  int32(count),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list weakest tasks in %s: %v", acsID, err)
+	}
+
+	tasks := make([]TaskSummary, len(rows))
+	for i, row := range rows {
+		tasks[i] = TaskSummary{
+			ID:           row.Task.ID,
+			AreaID:       row.AcsArea.ID,
+			AreaPublicID: row.AcsArea.PublicID,
+			PublicID:     row.Task.PublicID,
+			Name:         row.Task.Name,
+			Objective:    row.Task.Objective,
+			FullPublicID: row.FullPublicID,
+			Confidence:   Confidence{int(row.Votes), int(row.MaxVotes)},
+		}
+	}
+
+	return tasks, nil
+}
+
+func (m *ACSModel) GetTaskByArea(ctx context.Context, userID int32, acs string, areaID string, taskID string) (Task, error) {
 	row, err := m.q.GetTaskByPublicID(ctx, queries.GetTaskByPublicIDParams{
 		Acs:    acs,
 		AreaID: areaID,
 		TaskID: taskID,
+		UserID: userID,
 	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Task{}, newNotFoundError("task", fmt.Sprintf("%s.%s.%s", acs, areaID, taskID), err)
+		}
+
 		return Task{}, fmt.Errorf("failed to retrieve task %s.%s.%s: %v", acs, areaID, taskID, err)
 	}
 
@@ -192,6 +412,7 @@ func (m *ACSModel) GetTaskByArea(ctx context.Context, acs string, areaID string,
 		Name:       row.Task.Name,
 		Objective:  row.Task.Objective,
 		Note:       row.Task.Note,
+		AppliesTo:  row.Task.AppliesTo,
 		Area:       areaOfOperationFromModel(row.AcsArea),
 		Confidence: Confidence{int(row.Votes), int(row.MaxVotes)},
 	}
@@ -203,12 +424,19 @@ func (m *ACSModel) GetTaskByArea(ctx context.Context, acs string, areaID string,
 
 	task.References = references
 
-	return m.addElementsToTask(ctx, task)
+	return m.addElementsToTask(ctx, userID, task)
 }
 
-func (m *ACSModel) GetTaskByElementID(ctx context.Context, elementID int32) (Task, error) {
-	row, err := m.q.GetTaskByElementID(ctx, elementID)
+func (m *ACSModel) GetTaskByElementID(ctx context.Context, userID int32, elementID int32) (Task, error) {
+	row, err := m.q.GetTaskByElementID(ctx, queries.GetTaskByElementIDParams{
+		ElementID: elementID,
+		UserID:    userID,
+	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Task{}, newNotFoundError("element", elementID, err)
+		}
+
 		return Task{}, fmt.Errorf("failed to retrieve parent task for element %d: %v", elementID, err)
 	}
 
@@ -218,6 +446,7 @@ func (m *ACSModel) GetTaskByElementID(ctx context.Context, elementID int32) (Tas
 		Name:       row.Task.Name,
 		Objective:  row.Task.Objective,
 		Note:       row.Task.Note,
+		AppliesTo:  row.Task.AppliesTo,
 		Area:       areaOfOperationFromModel(row.AcsArea),
 		Confidence: Confidence{int(row.Votes), int(row.MaxVotes)},
 	}
@@ -229,7 +458,7 @@ func (m *ACSModel) GetTaskByElementID(ctx context.Context, elementID int32) (Tas
 
 	task.References = references
 
-	return m.addElementsToTask(ctx, task)
+	return m.addElementsToTask(ctx, userID, task)
 }
 
 func (m *ACSModel) getTaskReferences(ctx context.Context, taskID int32) ([]string, error) {
@@ -246,8 +475,8 @@ func (m *ACSModel) getTaskReferences(ctx context.Context, taskID int32) ([]strin
 	return refValues, nil
 }
 
-func (m *ACSModel) addElementsToTask(ctx context.Context, task Task) (Task, error) {
-	elements, err := m.listElementsForTask(ctx, task.ID)
+func (m *ACSModel) addElementsToTask(ctx context.Context, userID int32, task Task) (Task, error) {
+	elements, err := m.listElementsForTask(ctx, userID, task.ID)
 	if err != nil {
 		return Task{}, fmt.Errorf("failed to list elements for task: %v", err)
 	}
@@ -255,12 +484,16 @@ func (m *ACSModel) addElementsToTask(ctx context.Context, task Task) (Task, erro
 	task.KnowledgeElements = elements[TaskElementTypeKnowledge]
 	task.RiskManagementElements = elements[TaskElementTypeRiskManagement]
 	task.SkillElements = elements[TaskElementTypeSkills]
+	task.PTSElements = elements[TaskElementTypePTS]
 
 	return task, nil
 }
 
-func (m *ACSModel) listElementsForTask(ctx context.Context, taskID int32) (map[TaskElementType][]TaskElement, error) {
-	elements, err := m.q.ListElementsByTaskID(ctx, taskID)
+func (m *ACSModel) listElementsForTask(ctx context.Context, userID int32, taskID int32) (map[TaskElementType][]TaskElement, error) {
+	elements, err := m.q.ListElementsByTaskID(ctx, queries.ListElementsByTaskIDParams{
+		UserID: userID,
+		TaskID: taskID,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list task elements for task %d: %v", taskID, err)
 	}
@@ -270,7 +503,7 @@ func (m *ACSModel) listElementsForTask(ctx context.Context, taskID int32) (map[T
 		elementIDs = append(elementIDs, e.AcsElement.ID)
 	}
 
-	subElements, err := m.listSubElements(ctx, elementIDs)
+	subElements, err := m.listSubElements(ctx, userID, elementIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list sub-elements for elements: %v", err)
 	}
@@ -283,13 +516,14 @@ func (m *ACSModel) listElementsForTask(ctx context.Context, taskID int32) (map[T
 		}
 
 		element := TaskElement{
-			ID:           e.AcsElement.ID,
-			TaskID:       e.AcsElement.TaskID,
-			Type:         elementType,
-			PublicID:     e.AcsElement.PublicID,
-			Content:      e.AcsElement.Content,
-			FullPublicID: e.FullPublicID,
-			SubElements:  subElements[e.AcsElement.ID],
+			ID:              e.AcsElement.ID,
+			TaskID:          e.AcsElement.TaskID,
+			Type:            elementType,
+			PublicID:        e.AcsElement.PublicID,
+			Content:         e.AcsElement.Content,
+			FullPublicID:    e.FullPublicID,
+			SubElements:     subElements[e.AcsElement.ID],
+			MissedOnWritten: e.MissedOnWritten,
 		}
 
 		if e.ConfidenceVote.Valid {
@@ -297,12 +531,28 @@ func (m *ACSModel) listElementsForTask(ctx context.Context, taskID int32) (map[T
 			element.ConfidenceLevel = &level
 		}
 
+		if e.ReviewDueAt.Valid {
+			element.ReviewDueAt = &e.ReviewDueAt.Time
+		}
+
+		element.SignOff = signOffFromModel(e.SignedOffBy, e.SignedOffAt)
+
 		elementsByType[elementType] = append(
 			elementsByType[elementType],
 			element,
 		)
 	}
 
+	for _, group := range elementsByType {
+		if err := m.attachNotes(ctx, userID, group); err != nil {
+			return nil, err
+		}
+
+		if err := m.attachQuestionStats(ctx, userID, group); err != nil {
+			return nil, err
+		}
+	}
+
 	return elementsByType, nil
 }
 
@@ -316,13 +566,20 @@ func taskElementTypeFromModel(elementType queries.AcsElementType) TaskElementTyp
 
 	case queries.AcsElementTypeS:
 		return TaskElementTypeSkills
+
+	case queries.AcsElementTypeE:
+		return TaskElementTypePTS
 	}
 
 	panic(fmt.Sprintf("Unknown element type: %s", elementType))
 }
 
-func (m *ACSModel) listSubElements(ctx context.Context, elementIDs []int32) (map[int32][]SubElement, error) {
-	subElements, err := m.q.ListSubElementsByElementIDs(ctx, elementIDs)
+// listSubElements lists the sub-elements of each element that apply to the user's class rating.
+func (m *ACSModel) listSubElements(ctx context.Context, userID int32, elementIDs []int32) (map[int32][]SubElement, error) {
+	subElements, err := m.q.ListSubElementsByElementIDs(ctx, queries.ListSubElementsByElementIDsParams{
+		ElementIds: elementIDs,
+		UserID:     userID,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query sub-elements: %v", err)
 	}
@@ -347,35 +604,158 @@ func (m *ACSModel) listSubElements(ctx context.Context, elementIDs []int32) (map
 	return subElementsByElementID, nil
 }
 
+// GetElement retrieves a single element along with the user's confidence in it.
+func (m *ACSModel) GetElement(ctx context.Context, userID int32, elementID int32) (TaskElement, error) {
+	row, err := m.q.GetElementByID(ctx, queries.GetElementByIDParams{UserID: userID, ElementID: elementID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TaskElement{}, newNotFoundError("element", elementID, err)
+		}
+
+		return TaskElement{}, fmt.Errorf("failed to retrieve element %d: %v", elementID, err)
+	}
+
+	subElements, err := m.listSubElements(ctx, userID, []int32{elementID})
+	if err != nil {
+		return TaskElement{}, fmt.Errorf("failed to list sub-elements for element %d: %v", elementID, err)
+	}
+
+	element := TaskElement{
+		ID:              row.AcsElement.ID,
+		TaskID:          row.AcsElement.TaskID,
+		Type:            taskElementTypeFromModel(row.AcsElement.Type),
+		PublicID:        row.AcsElement.PublicID,
+		Content:         row.AcsElement.Content,
+		FullPublicID:    row.FullPublicID,
+		SubElements:     subElements[elementID],
+		MissedOnWritten: row.MissedOnWritten,
+	}
+
+	if row.ConfidenceVote.Valid {
+		level := ConfidenceLevel(row.ConfidenceVote.Int16)
+		element.ConfidenceLevel = &level
+	}
+
+	if row.ReviewDueAt.Valid {
+		element.ReviewDueAt = &row.ReviewDueAt.Time
+	}
+
+	element.SignOff = signOffFromModel(row.SignedOffBy, row.SignedOffAt)
+
+	return element, nil
+}
+
 func (m *ACSModel) GetElementPublicIDByID(ctx context.Context, elementID int32) (string, error) {
 	publicID, err := m.q.GetElementPublicIDByID(ctx, elementID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", newNotFoundError("element", elementID, err)
+		}
+
 		return "", fmt.Errorf("failed to retrieve public ID of element %d: %v", elementID, err)
 	}
 
 	return publicID, nil
 }
 
-func (m *ACSModel) SetElementConfidence(ctx context.Context, elementID int32, confidence ConfidenceLevel) error {
+// SetElementConfidence records a user's confidence in an element and schedules its next review.
+func (m *ACSModel) SetElementConfidence(ctx context.Context, userID int32, elementID int32, confidence ConfidenceLevel) error {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback element confidence transaction.", "error", err)
+		}
+	}()
+
+	schedule, err := recordElementConfidence(ctx, queries.New(tx), userID, elementID, confidence, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit element confidence: %v", err)
+	}
+
+	m.logger.InfoContext(
+		ctx,
+		"Set element confidence.",
+		"userID", userID,
+		"elementID", elementID,
+		"confidence", confidence,
+		"reviewDue", schedule.DueAt,
+	)
+
+	return nil
+}
+
+// recordElementConfidence saves a user's vote for an element and schedules its next review.
+func recordElementConfidence(
+	ctx context.Context,
+	q *queries.Queries,
+	userID int32,
+	elementID int32,
+	confidence ConfidenceLevel,
+	now time.Time,
+) (ReviewSchedule, error) {
 	params := queries.SetElementConfidenceParams{
+		UserID:    userID,
 		ElementID: elementID,
 		Vote:      int16(confidence),
 	}
-	if err := m.q.SetElementConfidence(ctx, params); err != nil {
-		return fmt.Errorf("failed to update confidence for element %d: %v", elementID, err)
+	if err := q.SetElementConfidence(ctx, params); err != nil {
+		if isForeignKeyViolation(err) {
+			return ReviewSchedule{}, newNotFoundError("element", elementID, err)
+		}
+
+		return ReviewSchedule{}, fmt.Errorf("failed to update confidence for element %d: %v", elementID, err)
 	}
 
-	m.logger.InfoContext(ctx, "Set element confidence.", "elementID", elementID, "confidence", confidence)
+	schedule, err := scheduleReview(ctx, q, userID, elementID, confidence, now)
+	if err != nil {
+		return ReviewSchedule{}, fmt.Errorf("failed to schedule review for element %d: %v", elementID, err)
+	}
 
-	return nil
+	return schedule, nil
 }
 
-func (m *ACSModel) ClearElementConfidence(ctx context.Context, elementID int32) error {
-	if err := m.q.ClearElementConfidence(ctx, elementID); err != nil {
+// ClearElementConfidence removes a user's confidence vote for an element along with its review
+// schedule.
+func (m *ACSModel) ClearElementConfidence(ctx context.Context, userID int32, elementID int32) error {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback element confidence transaction.", "error", err)
+		}
+	}()
+
+	q := queries.New(tx)
+
+	params := queries.ClearElementConfidenceParams{
+		UserID:    userID,
+		ElementID: elementID,
+	}
+	if err := q.ClearElementConfidence(ctx, params); err != nil {
 		return fmt.Errorf("failed to clear confidence for element %d: %v", elementID, err)
 	}
 
-	m.logger.InfoContext(ctx, "Cleared element confidence.", "elementID", elementID)
+	err = q.DeleteElementReview(ctx, queries.DeleteElementReviewParams{UserID: userID, ElementID: elementID})
+	if err != nil {
+		return fmt.Errorf("failed to clear review schedule for element %d: %v", elementID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit element confidence: %v", err)
+	}
+
+	m.logger.InfoContext(ctx, "Cleared element confidence.", "userID", userID, "elementID", elementID)
 
 	return nil
 }
@@ -385,8 +765,11 @@ type Confidence struct {
 	Possible int
 }
 
-func (m *ACSModel) GetTaskConfidence(ctx context.Context, taskID int32) (Confidence, error) {
-	result, err := m.q.GetTaskConfidenceByTaskID(ctx, taskID)
+func (m *ACSModel) GetTaskConfidence(ctx context.Context, userID int32, taskID int32) (Confidence, error) {
+	result, err := m.q.GetTaskConfidenceByTaskID(ctx, queries.GetTaskConfidenceByTaskIDParams{
+		UserID: userID,
+		TaskID: taskID,
+	})
 	if err != nil {
 		return Confidence{}, fmt.Errorf("failed to get task confidence: %v", err)
 	}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+// acsCodePattern matches element codes such as "PA.I.B.K3" as they are listed on an Airman
+// Knowledge Test Report.
+var acsCodePattern = regexp.MustCompile(`\b[A-Z]{2}\.[IVX]+\.[A-Z]\.[KRS]\d+\b`)
+
+// ParseACSCodes extracts the unique element codes from text, in the order they first appear. Any
+// text around the codes, such as the rest of a pasted test report, is ignored.
+func ParseACSCodes(text string) []string {
+	seen := make(map[string]bool)
+	codes := make([]string, 0)
+	for _, code := range acsCodePattern.FindAllString(strings.ToUpper(text), -1) {
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	return codes
+}
+
+type KnowledgeTestImportOptions struct {
+	// SetLowConfidence records a low confidence vote for each missed element in addition to
+	// flagging it as missed.
+	SetLowConfidence bool
+}
+
+// KnowledgeTestImport is the result of importing the codes from a knowledge test report.
+type KnowledgeTestImport struct {
+	// Matched lists the codes that were resolved to elements.
+	Matched []string
+
+	// Unknown lists the codes that don't match an element in any loaded ACS.
+	Unknown []string
+}
+
+// ImportKnowledgeTest flags the elements a user missed on their knowledge test. Codes that don't
+// exist are reported rather than causing the import to fail.
+func (m *ACSModel) ImportKnowledgeTest(
+	ctx context.Context,
+	userID int32,
+	codes []string,
+	opts KnowledgeTestImportOptions,
+) (KnowledgeTestImport, error) {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return KnowledgeTestImport{}, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback knowledge test import transaction.", "error", err)
+		}
+	}()
+
+	q := queries.New(tx)
+
+	rows, err := q.ListElementsByFullPublicIDs(ctx, queries.ListElementsByFullPublicIDsParams{
+		FullPublicIds: codes,
+		UserID:        userID,
+	})
+	if err != nil {
+		return KnowledgeTestImport{}, fmt.Errorf("failed to resolve element codes: %v", err)
+	}
+
+	elements := make(map[string]int32, len(rows))
+	for _, row := range rows {
+		elements[row.FullPublicID] = row.ID
+	}
+
+	now := time.Now()
+	result := KnowledgeTestImport{Matched: make([]string, 0), Unknown: make([]string, 0)}
+	for _, code := range codes {
+		elementID, ok := elements[code]
+		if !ok {
+			result.Unknown = append(result.Unknown, code)
+			continue
+		}
+
+		err := q.FlagMissedElement(ctx, queries.FlagMissedElementParams{UserID: userID, ElementID: elementID})
+		if err != nil {
+			return KnowledgeTestImport{}, fmt.Errorf("failed to flag %s as missed: %v", code, err)
+		}
+
+		if opts.SetLowConfidence {
+			err := q.SetElementConfidence(ctx, queries.SetElementConfidenceParams{
+				UserID:    userID,
+				ElementID: elementID,
+				Vote:      int16(ConfidenceLevelLow),
+			})
+			if err != nil {
+				return KnowledgeTestImport{}, fmt.Errorf("failed to set confidence for %s: %v", code, err)
+			}
+
+			if _, err := scheduleReview(ctx, q, userID, elementID, ConfidenceLevelLow, now); err != nil {
+				return KnowledgeTestImport{}, fmt.Errorf("failed to schedule review for %s: %v", code, err)
+			}
+		}
+
+		result.Matched = append(result.Matched, code)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return KnowledgeTestImport{}, fmt.Errorf("failed to commit knowledge test import: %v", err)
+	}
+
+	m.logger.InfoContext(
+		ctx,
+		"Imported knowledge test report.",
+		"userID", userID,
+		"matched", len(result.Matched),
+		"unknown", len(result.Unknown),
+		"lowConfidence", opts.SetLowConfidence,
+	)
+
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+)
+
+// ClassRating is an airplane class rating. Some tasks only apply to certain classes, such as the
+// seaplane tasks of the Private Pilot ACS.
+type ClassRating string
+
+const (
+	ClassRatingASEL ClassRating = "ASEL"
+	ClassRatingAMEL ClassRating = "AMEL"
+	ClassRatingASES ClassRating = "ASES"
+	ClassRatingAMES ClassRating = "AMES"
+)
+
+// ClassRatings lists every class rating in the order they appear in an ACS.
+var ClassRatings = []ClassRating{ClassRatingASEL, ClassRatingAMEL, ClassRatingASES, ClassRatingAMES}
+
+// ParseClassRating converts the abbreviation of a class rating into a ClassRating.
+func ParseClassRating(raw string) (ClassRating, error) {
+	if rating := ClassRating(raw); slices.Contains(ClassRatings, rating) {
+		return rating, nil
+	}
+
+	return "", fmt.Errorf("unknown class rating: %q", raw)
+}
+
+// Name returns the full name of the class rating.
+func (c ClassRating) Name() string {
+	switch c {
+	case ClassRatingASEL:
+		return "Airplane Single-Engine Land"
+
+	case ClassRatingAMEL:
+		return "Airplane Multiengine Land"
+
+	case ClassRatingASES:
+		return "Airplane Single-Engine Sea"
+
+	case ClassRatingAMES:
+		return "Airplane Multiengine Sea"
+	}
+
+	return string(c)
+}
+
+// SetClassRating changes the class rating a user is training for. Tasks that don't apply to the
+// class are left out of the user's task lists and confidence totals. A nil rating includes every
+// task.
+func (m *UserModel) SetClassRating(ctx context.Context, userID int32, rating *ClassRating) error {
+	var value queries.NullClassRating
+	if rating != nil {
+		value = queries.NullClassRating{ClassRating: queries.ClassRating(*rating), Valid: true}
+	}
+
+	err := m.q.SetUserClassRating(ctx, queries.SetUserClassRatingParams{ID: userID, ClassRating: value})
+	if err != nil {
+		return fmt.Errorf("failed to update class rating for user %d: %v", userID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ACSChangeKind string
+
+const (
+	ACSChangeAdded   ACSChangeKind = "added"
+	ACSChangeChanged ACSChangeKind = "changed"
+	ACSChangeRemoved ACSChangeKind = "removed"
+)
+
+// ACSChange describes a single difference between two versions of an ACS document.
+type ACSChange struct {
+	Kind ACSChangeKind `json:"kind"`
+
+	// Type is the kind of item that changed: "acs", "area", "task", "reference", "element", or
+	// "subElement".
+	Type string `json:"type"`
+
+	// ID is the full public ID of the item. References are identified by their position within
+	// their task, e.g. "PA.I.A.references[0]".
+	ID string `json:"id"`
+
+	// Field is the attribute that changed. It is only set for changes to existing items.
+	Field  string `json:"field,omitempty"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// ACSDiff is the set of changes between two versions of an ACS document.
+type ACSDiff struct {
+	Changes []ACSChange `json:"changes"`
+}
+
+// Count returns the number of changes of a particular kind.
+func (d ACSDiff) Count(kind ACSChangeKind) int {
+	count := 0
+	for _, c := range d.Changes {
+		if c.Kind == kind {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Empty indicates if there are no differences.
+func (d ACSDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+type diffField struct {
+	name  string
+	value string
+}
+
+type diffNode struct {
+	itemType string
+	id       string
+	fields   []diffField
+}
+
+func (n diffNode) key() string {
+	return n.itemType + " " + n.id
+}
+
+// flattenACS lists every item in an ACS in document order. Items are keyed the same way they are
+// matched when populating the database, so the flattened form mirrors what would be updated.
+func flattenACS(acs ExternalACS) []diffNode {
+	if acs.ID == "" {
+		return nil
+	}
+
+	nodes := []diffNode{{"acs", acs.ID, []diffField{
+		{"edition", acs.Edition},
+		{"kind", string(acs.DocumentKind())},
+		{"name", acs.Name},
+	}}}
+
+	for areaOrder, area := range acs.Areas {
+		areaID := fmt.Sprintf("%s.%s", acs.ID, area.ID)
+		nodes = append(nodes, diffNode{"area", areaID, []diffField{
+			{"name", area.Name},
+			{"order", strconv.Itoa(areaOrder)},
+		}})
+
+		for _, task := range area.Tasks {
+			taskID := fmt.Sprintf("%s.%s", areaID, task.ID)
+			nodes = append(nodes, diffNode{"task", taskID, []diffField{
+				{"name", task.Name},
+				{"objective", task.Objective},
+				{"note", task.Note},
+				{"appliesTo", strings.Join(task.AppliesTo, ", ")},
+			}})
+
+			for i, reference := range task.References {
+				nodes = append(nodes, diffNode{
+					"reference",
+					fmt.Sprintf("%s.references[%d]", taskID, i),
+					[]diffField{{"document", reference}},
+				})
+			}
+
+			elementGroups := []struct {
+				elementType TaskElementType
+				elements    []ExternalElement
+			}{
+				{TaskElementTypeKnowledge, task.Knowledge},
+				{TaskElementTypeRiskManagement, task.RiskManagement},
+				{TaskElementTypeSkills, task.Skills},
+				{TaskElementTypePTS, task.PTSElements},
+			}
+
+			for _, group := range elementGroups {
+				for _, element := range group.elements {
+					elementID := fmt.Sprintf("%s.%s%d", taskID, group.elementType, element.ID)
+					nodes = append(nodes, diffNode{"element", elementID, []diffField{
+						{"content", element.Content},
+						{"appliesTo", strings.Join(element.AppliesTo, ", ")},
+					}})
+
+					for i, subElement := range element.SubElements {
+						nodes = append(nodes, diffNode{
+							"subElement",
+							fmt.Sprintf("%s.%c", elementID, subElementAlphabet[i]),
+							[]diffField{
+								{"content", subElement.Content},
+								{"appliesTo", strings.Join(subElement.AppliesTo, ", ")},
+							},
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return nodes
+}
+
+// DiffACS computes the changes required to turn one version of an ACS into another. Additions and
+// changes are listed in the order they appear in the new document, followed by removals in the
+// order they appeared in the old one.
+func DiffACS(before ExternalACS, after ExternalACS) ACSDiff {
+	beforeNodes := flattenACS(before)
+	afterNodes := flattenACS(after)
+
+	beforeByKey := make(map[string]diffNode, len(beforeNodes))
+	for _, n := range beforeNodes {
+		beforeByKey[n.key()] = n
+	}
+
+	afterKeys := make(map[string]bool, len(afterNodes))
+
+	diff := ACSDiff{Changes: make([]ACSChange, 0)}
+	for _, n := range afterNodes {
+		afterKeys[n.key()] = true
+
+		previous, ok := beforeByKey[n.key()]
+		if !ok {
+			change := ACSChange{Kind: ACSChangeAdded, Type: n.itemType, ID: n.id}
+			if len(n.fields) > 0 {
+				change.After = n.fields[0].value
+			}
+
+			diff.Changes = append(diff.Changes, change)
+			continue
+		}
+
+		for i, field := range n.fields {
+			if previous.fields[i].value != field.value {
+				diff.Changes = append(diff.Changes, ACSChange{
+					Kind:   ACSChangeChanged,
+					Type:   n.itemType,
+					ID:     n.id,
+					Field:  field.name,
+					Before: previous.fields[i].value,
+					After:  field.value,
+				})
+			}
+		}
+	}
+
+	for _, n := range beforeNodes {
+		if afterKeys[n.key()] {
+			continue
+		}
+
+		change := ACSChange{Kind: ACSChangeRemoved, Type: n.itemType, ID: n.id}
+		if len(n.fields) > 0 {
+			change.Before = n.fields[0].value
+		}
+
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	return diff
+}
@@ -0,0 +1,369 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ACSEdit changes a copy of an ACS document. It returns the remapping needed to keep user data
+// with content that was given a different public ID, in the form accepted by PopulateOptions.
+type ACSEdit func(acs *ExternalACS) (map[string]string, error)
+
+// EditACS applies an edit to the stored copy of an ACS and saves the result. The edited document
+// must satisfy the ACS schema, otherwise an *ACSValidationError is returned. Removing elements
+// that carry user data fails with a *DataLossError unless allowDataLoss is set.
+func (m *ACSModel) EditACS(ctx context.Context, acsID string, edit ACSEdit, allowDataLoss bool) (PopulateReport, error) {
+	doc, err := m.ExportACS(ctx, acsID)
+	if err != nil {
+		return PopulateReport{}, err
+	}
+
+	remap, err := edit(&doc)
+	if err != nil {
+		return PopulateReport{}, err
+	}
+
+	if err := ValidateACS(doc); err != nil {
+		return PopulateReport{}, err
+	}
+
+	return m.PopulateACS(ctx, doc, PopulateOptions{Remap: remap, AllowDataLoss: allowDataLoss})
+}
+
+// Area finds an area of operation by its public ID.
+func (a *ExternalACS) Area(id string) (*ExternalArea, error) {
+	for i := range a.Areas {
+		if a.Areas[i].ID == id {
+			return &a.Areas[i], nil
+		}
+	}
+
+	return nil, &NotFoundError{Kind: "area", ID: a.ID + "." + id}
+}
+
+// Task finds a task by the public IDs of its area and itself.
+func (a *ExternalACS) Task(areaID string, taskID string) (*ExternalTask, error) {
+	area, err := a.Area(areaID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range area.Tasks {
+		if area.Tasks[i].ID == taskID {
+			return &area.Tasks[i], nil
+		}
+	}
+
+	return nil, &NotFoundError{Kind: "task", ID: fmt.Sprintf("%s.%s.%s", a.ID, areaID, taskID)}
+}
+
+// Elements returns the task's list of elements of a particular type.
+func (t *ExternalTask) Elements(elementType TaskElementType) *[]ExternalElement {
+	switch elementType {
+	case TaskElementTypeKnowledge:
+		return &t.Knowledge
+	case TaskElementTypeRiskManagement:
+		return &t.RiskManagement
+	case TaskElementTypePTS:
+		return &t.PTSElements
+	}
+
+	return &t.Skills
+}
+
+// elementIndex finds the position of an element within its task's list of elements of the same
+// type.
+func (t *ExternalTask) elementIndex(elementType TaskElementType, id int32) (int, error) {
+	i := slices.IndexFunc(*t.Elements(elementType), func(e ExternalElement) bool { return e.ID == id })
+	if i == -1 {
+		return 0, &NotFoundError{Kind: "element", ID: fmt.Sprintf("%s%d", elementType, id)}
+	}
+
+	return i, nil
+}
+
+// SetACSName renames an ACS.
+func SetACSName(name string) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		acs.Name = name
+
+		return nil, nil
+	}
+}
+
+// AddArea adds an empty area of operation to the end of an ACS.
+func AddArea(id string, name string) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		acs.Areas = append(acs.Areas, ExternalArea{ID: id, Name: name, Tasks: []ExternalTask{}})
+
+		return nil, nil
+	}
+}
+
+// RenameArea changes the name of an area of operation.
+func RenameArea(areaID string, name string) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		area, err := acs.Area(areaID)
+		if err != nil {
+			return nil, err
+		}
+
+		area.Name = name
+
+		return nil, nil
+	}
+}
+
+// MoveArea moves an area of operation up or down by one position. Areas are ordered by their
+// position rather than their ID, so their IDs are left alone.
+func MoveArea(areaID string, offset int) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		i := slices.IndexFunc(acs.Areas, func(a ExternalArea) bool { return a.ID == areaID })
+		if i == -1 {
+			return nil, &NotFoundError{Kind: "area", ID: acs.ID + "." + areaID}
+		}
+
+		j := i + offset
+		if j < 0 || j >= len(acs.Areas) {
+			return nil, nil
+		}
+
+		acs.Areas[i], acs.Areas[j] = acs.Areas[j], acs.Areas[i]
+
+		return nil, nil
+	}
+}
+
+// RemoveArea removes an area of operation along with its tasks.
+func RemoveArea(areaID string) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		if _, err := acs.Area(areaID); err != nil {
+			return nil, err
+		}
+
+		acs.Areas = slices.DeleteFunc(acs.Areas, func(a ExternalArea) bool { return a.ID == areaID })
+
+		return nil, nil
+	}
+}
+
+// AddTask adds a task without elements to an area. Tasks are listed by ID, so it is inserted in
+// order.
+func AddTask(areaID string, task ExternalTask) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		area, err := acs.Area(areaID)
+		if err != nil {
+			return nil, err
+		}
+
+		task.Knowledge = nil
+		task.RiskManagement = nil
+		task.Skills = nil
+		task.PTSElements = nil
+
+		i, _ := slices.BinarySearchFunc(area.Tasks, task.ID, func(t ExternalTask, id string) int {
+			return strings.Compare(t.ID, id)
+		})
+		area.Tasks = slices.Insert(area.Tasks, i, task)
+
+		return nil, nil
+	}
+}
+
+// UpdateTask replaces the name, objective, note, class ratings, and references of a task. Its
+// elements are left alone.
+func UpdateTask(areaID string, taskID string, update ExternalTask) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		task, err := acs.Task(areaID, taskID)
+		if err != nil {
+			return nil, err
+		}
+
+		task.Name = update.Name
+		task.Objective = update.Objective
+		task.Note = update.Note
+		task.AppliesTo = update.AppliesTo
+		task.References = update.References
+
+		return nil, nil
+	}
+}
+
+// MoveTask swaps a task with the one before or after it. Tasks are ordered by ID, so the two tasks
+// trade IDs and user data is remapped to follow their elements.
+func MoveTask(areaID string, taskID string, offset int) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		area, err := acs.Area(areaID)
+		if err != nil {
+			return nil, err
+		}
+
+		i := slices.IndexFunc(area.Tasks, func(t ExternalTask) bool { return t.ID == taskID })
+		if i == -1 {
+			return nil, &NotFoundError{Kind: "task", ID: fmt.Sprintf("%s.%s.%s", acs.ID, areaID, taskID)}
+		}
+
+		j := i + offset
+		if j < 0 || j >= len(area.Tasks) {
+			return nil, nil
+		}
+
+		first, second := &area.Tasks[i], &area.Tasks[j]
+		firstID := fmt.Sprintf("%s.%s.%s", acs.ID, areaID, first.ID)
+		secondID := fmt.Sprintf("%s.%s.%s", acs.ID, areaID, second.ID)
+
+		// Only tasks with elements can be remapped since there is nothing to move otherwise.
+		remap := make(map[string]string)
+		if taskHasElements(*first) {
+			remap[firstID] = secondID
+		}
+
+		if taskHasElements(*second) {
+			remap[secondID] = firstID
+		}
+
+		first.ID, second.ID = second.ID, first.ID
+		area.Tasks[i], area.Tasks[j] = area.Tasks[j], area.Tasks[i]
+
+		return remap, nil
+	}
+}
+
+func taskHasElements(t ExternalTask) bool {
+	return len(t.Knowledge)+len(t.RiskManagement)+len(t.Skills)+len(t.PTSElements) > 0
+}
+
+// RemoveTask removes a task along with its elements.
+func RemoveTask(areaID string, taskID string) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		if _, err := acs.Task(areaID, taskID); err != nil {
+			return nil, err
+		}
+
+		area, _ := acs.Area(areaID)
+		area.Tasks = slices.DeleteFunc(area.Tasks, func(t ExternalTask) bool { return t.ID == taskID })
+
+		return nil, nil
+	}
+}
+
+// AddElement adds an element to the end of a task's elements of the same type, numbering it after
+// the last one.
+func AddElement(areaID string, taskID string, elementType TaskElementType, element ExternalElement) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		task, err := acs.Task(areaID, taskID)
+		if err != nil {
+			return nil, err
+		}
+
+		elements := task.Elements(elementType)
+
+		element.ID = 1
+		if len(*elements) > 0 {
+			element.ID = (*elements)[len(*elements)-1].ID + 1
+		}
+
+		*elements = append(*elements, element)
+
+		return nil, nil
+	}
+}
+
+// UpdateElement replaces the content and sub-elements of an element. Notes on sub-elements belong
+// to a position, so they stay in place if sub-elements are reordered. The classes of the element,
+// and of sub-elements whose content is unchanged, are kept unless the update sets them.
+func UpdateElement(areaID string, taskID string, elementType TaskElementType, id int32, update ExternalElement) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		task, err := acs.Task(areaID, taskID)
+		if err != nil {
+			return nil, err
+		}
+
+		i, err := task.elementIndex(elementType, id)
+		if err != nil {
+			return nil, err
+		}
+
+		element := &(*task.Elements(elementType))[i]
+		element.Content = update.Content
+
+		if update.AppliesTo != nil {
+			element.AppliesTo = update.AppliesTo
+		}
+
+		for j, subElement := range update.SubElements {
+			if subElement.AppliesTo != nil {
+				continue
+			}
+
+			k := slices.IndexFunc(element.SubElements, func(s ExternalSubElement) bool {
+				return s.Content == subElement.Content
+			})
+			if k >= 0 {
+				update.SubElements[j].AppliesTo = element.SubElements[k].AppliesTo
+			}
+		}
+
+		element.SubElements = update.SubElements
+
+		return nil, nil
+	}
+}
+
+// MoveElement swaps an element's content with the element before or after it. Element numbers
+// describe their order, so the numbers stay in place and user data is remapped to follow the
+// content.
+func MoveElement(areaID string, taskID string, elementType TaskElementType, id int32, offset int) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		task, err := acs.Task(areaID, taskID)
+		if err != nil {
+			return nil, err
+		}
+
+		i, err := task.elementIndex(elementType, id)
+		if err != nil {
+			return nil, err
+		}
+
+		elements := *task.Elements(elementType)
+
+		j := i + offset
+		if j < 0 || j >= len(elements) {
+			return nil, nil
+		}
+
+		first, second := &elements[i], &elements[j]
+		taskPrefix := fmt.Sprintf("%s.%s.%s.%s", acs.ID, areaID, taskID, elementType)
+		firstID := fmt.Sprintf("%s%d", taskPrefix, first.ID)
+		secondID := fmt.Sprintf("%s%d", taskPrefix, second.ID)
+
+		first.Content, second.Content = second.Content, first.Content
+		first.AppliesTo, second.AppliesTo = second.AppliesTo, first.AppliesTo
+		first.SubElements, second.SubElements = second.SubElements, first.SubElements
+
+		return map[string]string{firstID: secondID, secondID: firstID}, nil
+	}
+}
+
+// RemoveElement removes an element. The remaining elements keep their numbers so that they still
+// match the published ACS.
+func RemoveElement(areaID string, taskID string, elementType TaskElementType, id int32) ACSEdit {
+	return func(acs *ExternalACS) (map[string]string, error) {
+		task, err := acs.Task(areaID, taskID)
+		if err != nil {
+			return nil, err
+		}
+
+		i, err := task.elementIndex(elementType, id)
+		if err != nil {
+			return nil, err
+		}
+
+		elements := task.Elements(elementType)
+		*elements = slices.Delete(*elements, i, i+1)
+
+		return nil, nil
+	}
+}
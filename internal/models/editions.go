@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListEditions returns every edition of the ACS that an edition belongs to, starting with the
+// current edition and followed by the rest from newest to oldest.
+func (m *ACSModel) ListEditions(ctx context.Context, acsID string) ([]ACS, error) {
+	rows, err := m.q.ListEditions(ctx, acsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list editions of %s: %v", acsID, err)
+	}
+
+	editions := make([]ACS, len(rows))
+	for i, row := range rows {
+		editions[i] = acsFromModel(row)
+	}
+
+	return editions, nil
+}
+
+// SetCurrentEdition makes an edition the current edition of its ACS.
+func (m *ACSModel) SetCurrentEdition(ctx context.Context, acsID string) error {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback current edition transaction.", "error", err)
+		}
+	}()
+
+	q := queries.New(tx)
+
+	// The previous edition has to be cleared first since only one edition can be current.
+	if err := q.ClearCurrentEdition(ctx, acsID); err != nil {
+		return fmt.Errorf("failed to clear current edition: %v", err)
+	}
+
+	updated, err := q.SetCurrentEdition(ctx, acsID)
+	if err != nil {
+		return fmt.Errorf("failed to set current edition: %v", err)
+	}
+
+	if updated == 0 {
+		return &NotFoundError{Kind: "ACS", ID: acsID}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit current edition: %v", err)
+	}
+
+	m.logger.InfoContext(ctx, "Set current ACS edition.", "acs", acsID)
+
+	return nil
+}
+
+// CompareEditions lists the changes between two editions of the same ACS. Items are matched by
+// their public IDs, so renumbered elements show up as changed content.
+func (m *ACSModel) CompareEditions(ctx context.Context, fromID string, toID string) (ACSDiff, error) {
+	from, err := m.ExportACS(ctx, fromID)
+	if err != nil {
+		return ACSDiff{}, err
+	}
+
+	to, err := m.ExportACS(ctx, toID)
+	if err != nil {
+		return ACSDiff{}, err
+	}
+
+	if from.ID != to.ID {
+		return ACSDiff{}, fmt.Errorf("%s and %s are not editions of the same ACS", fromID, toID)
+	}
+
+	return DiffACS(from, to), nil
+}
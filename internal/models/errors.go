@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrNotFound matches every NotFoundError when used with errors.Is.
+var ErrNotFound = errors.New("models: not found")
+
+// NotFoundError is returned when a requested record does not exist. It wraps the underlying
+// database error, usually pgx.ErrNoRows.
+type NotFoundError struct {
+	// Kind is the type of record that was requested, e.g. "area".
+	Kind string
+
+	// ID identifies the requested record.
+	ID string
+
+	err error
+}
+
+func newNotFoundError(kind string, id any, err error) *NotFoundError {
+	return &NotFoundError{Kind: kind, ID: fmt.Sprint(id), err: err}
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("models: %s %s not found", e.Kind, e.ID)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.err
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// isForeignKeyViolation indicates if an error was caused by referencing a row that doesn't exist.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
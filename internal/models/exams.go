@@ -0,0 +1,338 @@
+package models
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+type ExamGrade string
+
+const (
+	ExamGradePass    ExamGrade = "pass"
+	ExamGradeDiscuss ExamGrade = "discuss"
+	ExamGradeFail    ExamGrade = "fail"
+)
+
+// ParseExamGrade converts the name of a grade into an ExamGrade.
+func ParseExamGrade(raw string) (ExamGrade, error) {
+	switch grade := ExamGrade(raw); grade {
+	case ExamGradePass, ExamGradeDiscuss, ExamGradeFail:
+		return grade, nil
+	}
+
+	return "", fmt.Errorf("unknown exam grade: %q", raw)
+}
+
+// ExamOptions controls how a mock exam is generated.
+type ExamOptions struct {
+	ACS string
+
+	// Seed makes task selection repeatable. The same seed and confidence votes produce the same
+	// plan.
+	Seed int64
+
+	// TasksPerArea is the number of tasks picked from each area of operation. Areas with fewer
+	// tasks contribute all of them.
+	TasksPerArea int
+
+	// Scenario describes the flight the exam is built around.
+	Scenario string
+}
+
+// ExamSummary describes a mock exam without its tasks.
+type ExamSummary struct {
+	ID      int32
+	ACS     string
+	ACSName string
+
+	StudentID        int32
+	StudentUsername  string
+	ExaminerID       int32
+	ExaminerUsername string
+
+	Seed      int64
+	Scenario  string
+	CreatedAt time.Time
+
+	TaskCount int
+}
+
+// Exam is a mock oral exam plan along with the grades recorded so far.
+type Exam struct {
+	ExamSummary
+
+	Tasks []Task
+
+	// Grades maps element IDs to the grade the examiner gave. Ungraded elements are absent.
+	Grades map[int32]ExamGrade
+}
+
+// GradeCount returns the number of elements given a particular grade.
+func (e Exam) GradeCount(grade ExamGrade) int {
+	count := 0
+	for _, g := range e.Grades {
+		if g == grade {
+			count++
+		}
+	}
+
+	return count
+}
+
+// ElementCount returns the number of elements across every task on the exam.
+func (e Exam) ElementCount() int {
+	count := 0
+	for _, t := range e.Tasks {
+		count += len(t.KnowledgeElements) + len(t.RiskManagementElements) + len(t.SkillElements) + len(t.PTSElements)
+	}
+
+	return count
+}
+
+// pickExamTasks selects up to n tasks from an area, favoring tasks the student has less confidence
+// in. The selection is returned in ACS order.
+func pickExamTasks(rng *rand.Rand, tasks []TaskSummary, n int) []TaskSummary {
+	if len(tasks) <= n {
+		return tasks
+	}
+
+	type keyedTask struct {
+		task TaskSummary
+		key  float64
+	}
+
+	// Weighted sampling without replacement (Efraimidis-Spirakis): each task draws u^(1/w) for a
+	// uniform u and the highest keys win. Weights range from 1 for full confidence to 4 for none.
+	keyed := make([]keyedTask, len(tasks))
+	for i, t := range tasks {
+		confidence := 0.0
+		if t.Confidence.Possible > 0 {
+			confidence = float64(t.Confidence.Votes) / float64(t.Confidence.Possible)
+		}
+
+		weight := 4 - 3*confidence
+		keyed[i] = keyedTask{t, math.Pow(rng.Float64(), 1/weight)}
+	}
+
+	slices.SortStableFunc(keyed, func(a, b keyedTask) int { return cmp.Compare(b.key, a.key) })
+
+	picked := make([]TaskSummary, n)
+	for i := range picked {
+		picked[i] = keyed[i].task
+	}
+
+	slices.SortFunc(picked, func(a, b TaskSummary) int { return cmp.Compare(a.PublicID, b.PublicID) })
+
+	return picked
+}
+
+// CreateExam generates a mock exam for a student by picking tasks from each area of an ACS, and
+// returns the exam's ID.
+func (m *ACSModel) CreateExam(ctx context.Context, examinerID int32, studentID int32, opts ExamOptions) (int32, error) {
+	areas, err := m.ListAreasByACS(ctx, studentID, opts.ACS)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list areas for exam: %v", err)
+	}
+
+	if len(areas) == 0 {
+		return 0, newNotFoundError("ACS", opts.ACS, pgx.ErrNoRows)
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(opts.Seed), 0))
+
+	var tasks []TaskSummary
+	for _, area := range areas {
+		areaTasks, err := m.ListTasksByArea(ctx, studentID, area.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list tasks for exam: %v", err)
+		}
+
+		tasks = append(tasks, pickExamTasks(rng, areaTasks, opts.TasksPerArea)...)
+	}
+
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback exam transaction.", "error", err)
+		}
+	}()
+
+	q := queries.New(tx)
+
+	exam, err := q.CreateExam(ctx, queries.CreateExamParams{
+		AcsID:      opts.ACS,
+		StudentID:  studentID,
+		ExaminerID: examinerID,
+		Seed:       opts.Seed,
+		Scenario:   opts.Scenario,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create exam: %v", err)
+	}
+
+	for i, t := range tasks {
+		err := q.AddExamTask(ctx, queries.AddExamTaskParams{ExamID: exam.ID, TaskID: t.ID, Position: int32(i)})
+		if err != nil {
+			return 0, fmt.Errorf("failed to add task %s to exam: %v", t.FullPublicID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit exam: %v", err)
+	}
+
+	m.logger.InfoContext(
+		ctx,
+		"Created exam.",
+		"examID", exam.ID,
+		"acs", opts.ACS,
+		"studentID", studentID,
+		"examinerID", examinerID,
+		"tasks", len(tasks),
+	)
+
+	return exam.ID, nil
+}
+
+// ListExams returns the exams a user has taken or given, newest first.
+func (m *ACSModel) ListExams(ctx context.Context, userID int32) ([]ExamSummary, error) {
+	rows, err := m.q.ListExamsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exams for user %d: %v", userID, err)
+	}
+
+	exams := make([]ExamSummary, len(rows))
+	for i, row := range rows {
+		exams[i] = ExamSummary{
+			ID:               row.Exam.ID,
+			ACS:              row.Exam.AcsID,
+			ACSName:          row.AcsName,
+			StudentID:        row.Exam.StudentID,
+			StudentUsername:  row.StudentUsername,
+			ExaminerID:       row.Exam.ExaminerID,
+			ExaminerUsername: row.ExaminerUsername,
+			Seed:             row.Exam.Seed,
+			Scenario:         row.Exam.Scenario,
+			CreatedAt:        row.Exam.CreatedAt.Time,
+			TaskCount:        int(row.TaskCount),
+		}
+	}
+
+	return exams, nil
+}
+
+// GetExam retrieves an exam plan with its grades. Exams can only be viewed by their student and
+// examiner. Element confidence is the student's, and the student's notes are only included when
+// they are the one viewing the exam.
+func (m *ACSModel) GetExam(ctx context.Context, userID int32, examID int32) (Exam, error) {
+	row, err := m.q.GetExam(ctx, queries.GetExamParams{ExamID: examID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Exam{}, newNotFoundError("exam", examID, err)
+		}
+
+		return Exam{}, fmt.Errorf("failed to retrieve exam %d: %v", examID, err)
+	}
+
+	exam := Exam{
+		ExamSummary: ExamSummary{
+			ID:               row.Exam.ID,
+			ACS:              row.Exam.AcsID,
+			ACSName:          row.AcsName,
+			StudentID:        row.Exam.StudentID,
+			StudentUsername:  row.StudentUsername,
+			ExaminerID:       row.Exam.ExaminerID,
+			ExaminerUsername: row.ExaminerUsername,
+			Seed:             row.Exam.Seed,
+			Scenario:         row.Exam.Scenario,
+			CreatedAt:        row.Exam.CreatedAt.Time,
+		},
+		Grades: make(map[int32]ExamGrade),
+	}
+
+	taskRows, err := m.q.ListExamTasks(ctx, examID)
+	if err != nil {
+		return Exam{}, fmt.Errorf("failed to list tasks for exam %d: %v", examID, err)
+	}
+
+	exam.TaskCount = len(taskRows)
+	exam.Tasks = make([]Task, len(taskRows))
+	for i, t := range taskRows {
+		task := Task{
+			ID:        t.Task.ID,
+			PublicID:  t.Task.PublicID,
+			Name:      t.Task.Name,
+			Objective: t.Task.Objective,
+			Note:      t.Task.Note,
+			Area:      areaOfOperationFromModel(t.AcsArea),
+		}
+
+		task.References, err = m.getTaskReferences(ctx, task.ID)
+		if err != nil {
+			return Exam{}, fmt.Errorf("failed to fetch references: %v", err)
+		}
+
+		exam.Tasks[i], err = m.addElementsToTask(ctx, exam.StudentID, task)
+		if err != nil {
+			return Exam{}, err
+		}
+
+		// Elements are loaded for the student so the exam reflects their confidence, but their
+		// notes are private to them.
+		if userID != exam.StudentID {
+			clearTaskNotes(&exam.Tasks[i])
+		}
+	}
+
+	grades, err := m.q.ListExamGrades(ctx, examID)
+	if err != nil {
+		return Exam{}, fmt.Errorf("failed to list grades for exam %d: %v", examID, err)
+	}
+
+	for _, g := range grades {
+		exam.Grades[g.ElementID] = ExamGrade(g.Grade)
+	}
+
+	return exam, nil
+}
+
+// GradeExamElement records the examiner's grade for an element on an exam. A not found error is
+// returned if the user isn't the exam's examiner or the element isn't part of the exam.
+func (m *ACSModel) GradeExamElement(
+	ctx context.Context,
+	examinerID int32,
+	examID int32,
+	elementID int32,
+	grade ExamGrade,
+) error {
+	updated, err := m.q.GradeExamElement(ctx, queries.GradeExamElementParams{
+		Grade:      queries.ExamGrade(grade),
+		ExamID:     examID,
+		ExaminerID: examinerID,
+		ElementID:  elementID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to grade element %d on exam %d: %v", elementID, examID, err)
+	}
+
+	if updated == 0 {
+		return newNotFoundError("exam element", fmt.Sprintf("%d/%d", examID, elementID), pgx.ErrNoRows)
+	}
+
+	m.logger.InfoContext(ctx, "Graded exam element.", "examID", examID, "elementID", elementID, "grade", grade)
+
+	return nil
+}
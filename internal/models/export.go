@@ -0,0 +1,186 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+// ACSSchemaRef is the schema reference used by the documents in the acs directory.
+const ACSSchemaRef = "./schema/acs.json"
+
+// ExportACS reconstructs the external representation of an ACS from the database. The document can
+// be loaded with PopulateACS, and it references the schema the same way as the documents in the acs
+// directory.
+func (m *ACSModel) ExportACS(ctx context.Context, acsID string) (ExternalACS, error) {
+	acs, err := exportACS(ctx, &m.q, acsID)
+	if err != nil {
+		return ExternalACS{}, err
+	}
+
+	if acs.ID == "" {
+		return ExternalACS{}, &NotFoundError{Kind: "ACS", ID: acsID}
+	}
+
+	acs.Schema = ACSSchemaRef
+
+	return acs, nil
+}
+
+// EncodeACS writes an ACS document in the same format as the documents in the acs directory. The
+// output is stable, so an exported document can be diffed against the file it was loaded from.
+func EncodeACS(w io.Writer, acs ExternalACS) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(acs); err != nil {
+		return fmt.Errorf("failed to encode ACS %s: %v", acs.EditionID(), err)
+	}
+
+	return nil
+}
+
+// exportACS reconstructs the external representation of an ACS from the database. If the ACS does
+// not exist, an empty document is returned.
+func exportACS(ctx context.Context, q *queries.Queries, acsID string) (ExternalACS, error) {
+	acsModel, err := q.GetACSDocument(ctx, acsID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ExternalACS{}, nil
+		}
+
+		return ExternalACS{}, fmt.Errorf("failed to retrieve ACS %s: %v", acsID, err)
+	}
+
+	areas, err := q.ListAreasForExport(ctx, acsID)
+	if err != nil {
+		return ExternalACS{}, fmt.Errorf("failed to list areas: %v", err)
+	}
+
+	tasks, err := q.ListTasksForExport(ctx, acsID)
+	if err != nil {
+		return ExternalACS{}, fmt.Errorf("failed to list tasks: %v", err)
+	}
+
+	references, err := q.ListTaskReferencesForExport(ctx, acsID)
+	if err != nil {
+		return ExternalACS{}, fmt.Errorf("failed to list task references: %v", err)
+	}
+
+	elements, err := q.ListElementsForExport(ctx, acsID)
+	if err != nil {
+		return ExternalACS{}, fmt.Errorf("failed to list elements: %v", err)
+	}
+
+	subElements, err := q.ListSubElementsForExport(ctx, acsID)
+	if err != nil {
+		return ExternalACS{}, fmt.Errorf("failed to list sub-elements: %v", err)
+	}
+
+	// Each level is built bottom up so that children are complete before they are copied into
+	// their parents.
+	subElementsByElement := make(map[int32][]ExternalSubElement)
+	for _, s := range subElements {
+		subElementsByElement[s.ElementID] = append(subElementsByElement[s.ElementID], ExternalSubElement{
+			Content:   s.Content,
+			AppliesTo: s.AppliesTo,
+		})
+	}
+
+	type taskElements struct {
+		knowledge      []ExternalElement
+		riskManagement []ExternalElement
+		skills         []ExternalElement
+		pts            []ExternalElement
+	}
+
+	elementsByTask := make(map[int32]*taskElements)
+	for _, e := range elements {
+		if _, ok := elementsByTask[e.TaskID]; !ok {
+			elementsByTask[e.TaskID] = &taskElements{}
+		}
+
+		element := ExternalElement{
+			ID:          e.PublicID,
+			Content:     e.Content,
+			AppliesTo:   e.AppliesTo,
+			SubElements: subElementsByElement[e.ID],
+		}
+
+		grouped := elementsByTask[e.TaskID]
+		switch taskElementTypeFromModel(e.Type) {
+		case TaskElementTypeKnowledge:
+			grouped.knowledge = append(grouped.knowledge, element)
+		case TaskElementTypeRiskManagement:
+			grouped.riskManagement = append(grouped.riskManagement, element)
+		case TaskElementTypeSkills:
+			grouped.skills = append(grouped.skills, element)
+		case TaskElementTypePTS:
+			grouped.pts = append(grouped.pts, element)
+		}
+	}
+
+	referencesByTask := make(map[int32][]string)
+	for _, r := range references {
+		referencesByTask[r.TaskID] = append(referencesByTask[r.TaskID], r.Document)
+	}
+
+	tasksByArea := make(map[int32][]ExternalTask)
+	for _, t := range tasks {
+		task := ExternalTask{
+			ID:         t.PublicID,
+			Name:       t.Name,
+			Objective:  t.Objective,
+			Note:       t.Note,
+			AppliesTo:  t.AppliesTo,
+			References: referencesByTask[t.ID],
+		}
+
+		if grouped, ok := elementsByTask[t.ID]; ok {
+			task.Knowledge = grouped.knowledge
+			task.RiskManagement = grouped.riskManagement
+			task.Skills = grouped.skills
+			task.PTSElements = grouped.pts
+		}
+
+		tasksByArea[t.AreaID] = append(tasksByArea[t.AreaID], task)
+	}
+
+	acs := ExternalACS{
+		ID:      acsModel.Code,
+		Edition: acsModel.Edition,
+		Name:    acsModel.Name,
+		Areas:   make([]ExternalArea, len(areas)),
+	}
+
+	// Documents without a kind are an ACS, so it is only written out for PTS documents.
+	if kind := ACSKind(acsModel.Kind); kind != ACSKindACS {
+		acs.Kind = kind
+	}
+
+	for i, a := range areas {
+		acs.Areas[i] = ExternalArea{
+			ID:    a.PublicID,
+			Name:  a.Name,
+			Tasks: emptyIfNil(tasksByArea[a.ID]),
+		}
+	}
+
+	return acs, nil
+}
+
+// emptyIfNil replaces a nil slice with an empty one so it is encoded as an empty JSON array rather
+// than null. Areas are required to have a list of tasks, even if they have none yet.
+func emptyIfNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+
+	return s
+}
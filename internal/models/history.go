@@ -0,0 +1,64 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+)
+
+// ConfidenceHistoryWeeks is the number of weeks of history shown for areas and tasks.
+const ConfidenceHistoryWeeks = 12
+
+// ConfidenceSnapshot is a user's confidence at the end of a particular week.
+type ConfidenceSnapshot struct {
+	WeekStart  time.Time
+	Confidence Confidence
+}
+
+// GetAreaConfidenceHistory returns the user's confidence in an area at the end of each of the last
+// several weeks, oldest first.
+func (m *ACSModel) GetAreaConfidenceHistory(ctx context.Context, userID int32, areaID int32) ([]ConfidenceSnapshot, error) {
+	rows, err := m.q.ListAreaConfidenceHistory(ctx, queries.ListAreaConfidenceHistoryParams{
+		UserID: userID,
+		Weeks:  ConfidenceHistoryWeeks,
+		AreaID: areaID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list confidence history for area %d: %v", areaID, err)
+	}
+
+	history := make([]ConfidenceSnapshot, len(rows))
+	for i, row := range rows {
+		history[i] = ConfidenceSnapshot{
+			WeekStart:  row.WeekStart.Time,
+			Confidence: Confidence{Votes: int(row.Votes), Possible: int(row.Possible)},
+		}
+	}
+
+	return history, nil
+}
+
+// GetTaskConfidenceHistory returns the user's confidence in a task at the end of each of the last
+// several weeks, oldest first.
+func (m *ACSModel) GetTaskConfidenceHistory(ctx context.Context, userID int32, taskID int32) ([]ConfidenceSnapshot, error) {
+	rows, err := m.q.ListTaskConfidenceHistory(ctx, queries.ListTaskConfidenceHistoryParams{
+		UserID: userID,
+		Weeks:  ConfidenceHistoryWeeks,
+		TaskID: taskID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list confidence history for task %d: %v", taskID, err)
+	}
+
+	history := make([]ConfidenceSnapshot, len(rows))
+	for i, row := range rows {
+		history[i] = ConfidenceSnapshot{
+			WeekStart:  row.WeekStart.Time,
+			Confidence: Confidence{Votes: int(row.Votes), Possible: int(row.Possible)},
+		}
+	}
+
+	return history, nil
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+type UserRole string
+
+const (
+	UserRoleStudent    UserRole = "student"
+	UserRoleInstructor UserRole = "instructor"
+)
+
+// ParseUserRole converts the name of a role into a UserRole.
+func ParseUserRole(raw string) (UserRole, error) {
+	switch role := UserRole(raw); role {
+	case UserRoleStudent, UserRoleInstructor:
+		return role, nil
+	}
+
+	return "", fmt.Errorf("unknown role: %q", raw)
+}
+
+// Student is a user assigned to an instructor.
+type Student struct {
+	User
+
+	// LastActiveAt is the last time the student rated an element, edited a note, or started a study
+	// session. It is nil if they haven't done any of those.
+	LastActiveAt *time.Time
+}
+
+// SetRole changes a user's role. Instructors who are made students lose their assigned students.
+func (m *UserModel) SetRole(ctx context.Context, username string, role UserRole) error {
+	id, err := m.q.SetUserRole(ctx, queries.SetUserRoleParams{Username: username, Role: queries.UserRole(role)})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no user named %s", username)
+		}
+
+		return fmt.Errorf("failed to update role for %s: %v", username, err)
+	}
+
+	if role != UserRoleInstructor {
+		if err := m.q.UnassignAllStudents(ctx, id); err != nil {
+			return fmt.Errorf("failed to unassign students from %s: %v", username, err)
+		}
+	}
+
+	m.logger.InfoContext(ctx, "Updated user role.", "username", username, "role", role)
+
+	return nil
+}
+
+// AssignStudent assigns a student to an instructor. Assigning a student twice has no effect.
+func (m *UserModel) AssignStudent(ctx context.Context, instructorUsername string, studentUsername string) error {
+	instructor, student, err := m.getAssignment(ctx, instructorUsername, studentUsername)
+	if err != nil {
+		return err
+	}
+
+	if !instructor.IsInstructor() {
+		return fmt.Errorf("%s is not an instructor", instructorUsername)
+	}
+
+	if instructor.ID == student.ID {
+		return fmt.Errorf("%s can't be their own student", instructorUsername)
+	}
+
+	err = m.q.AssignStudent(ctx, queries.AssignStudentParams{InstructorID: instructor.ID, StudentID: student.ID})
+	if err != nil {
+		return fmt.Errorf("failed to assign %s to %s: %v", studentUsername, instructorUsername, err)
+	}
+
+	m.logger.InfoContext(ctx, "Assigned student.", "instructor", instructorUsername, "student", studentUsername)
+
+	return nil
+}
+
+// UnassignStudent removes a student from an instructor.
+func (m *UserModel) UnassignStudent(ctx context.Context, instructorUsername string, studentUsername string) error {
+	instructor, student, err := m.getAssignment(ctx, instructorUsername, studentUsername)
+	if err != nil {
+		return err
+	}
+
+	removed, err := m.q.UnassignStudent(ctx, queries.UnassignStudentParams{
+		InstructorID: instructor.ID,
+		StudentID:    student.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unassign %s from %s: %v", studentUsername, instructorUsername, err)
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("%s is not a student of %s", studentUsername, instructorUsername)
+	}
+
+	m.logger.InfoContext(ctx, "Unassigned student.", "instructor", instructorUsername, "student", studentUsername)
+
+	return nil
+}
+
+func (m *UserModel) getAssignment(ctx context.Context, instructorUsername string, studentUsername string) (User, User, error) {
+	instructor, err := m.GetByUsername(ctx, instructorUsername)
+	if err != nil {
+		return User{}, User{}, err
+	}
+
+	student, err := m.GetByUsername(ctx, studentUsername)
+	if err != nil {
+		return User{}, User{}, err
+	}
+
+	return instructor, student, nil
+}
+
+// ListStudents returns the students assigned to an instructor, ordered by username.
+func (m *UserModel) ListStudents(ctx context.Context, instructorID int32) ([]Student, error) {
+	rows, err := m.q.ListStudents(ctx, instructorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students of user %d: %v", instructorID, err)
+	}
+
+	students := make([]Student, len(rows))
+	for i, row := range rows {
+		students[i] = Student{User: userFromModel(row.User)}
+		if row.LastActiveAt.Valid {
+			students[i].LastActiveAt = &row.LastActiveAt.Time
+		}
+	}
+
+	return students, nil
+}
+
+// GetStudent returns one of an instructor's students. Users who aren't assigned to the instructor
+// are reported as not found.
+func (m *UserModel) GetStudent(ctx context.Context, instructorID int32, studentID int32) (User, error) {
+	row, err := m.q.GetStudent(ctx, queries.GetStudentParams{InstructorID: instructorID, StudentID: studentID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, newNotFoundError("student", studentID, err)
+		}
+
+		return User{}, fmt.Errorf("failed to retrieve student %d of user %d: %v", studentID, instructorID, err)
+	}
+
+	return userFromModel(row.User), nil
+}
+
+// GetStudentByUsername retrieves one of an instructor's students by their username. Users who
+// aren't assigned to the instructor are reported as not found.
+func (m *UserModel) GetStudentByUsername(ctx context.Context, instructorID int32, username string) (User, error) {
+	row, err := m.q.GetStudentByUsername(ctx, queries.GetStudentByUsernameParams{
+		InstructorID: instructorID,
+		Username:     username,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, newNotFoundError("student", username, err)
+		}
+
+		return User{}, fmt.Errorf("failed to retrieve student %s of user %d: %v", username, instructorID, err)
+	}
+
+	return userFromModel(row.User), nil
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxNoteLength is the longest note, in characters, that can be saved.
+const MaxNoteLength = 10000
+
+// Note is a user's personal markdown note on an element or sub-element.
+type Note struct {
+	Content   string
+	UpdatedAt time.Time
+}
+
+type subElementNoteKey struct {
+	elementID int32
+	order     int32
+}
+
+// validateNote normalizes note content and reports if it is too long.
+func validateNote(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if utf8.RuneCountInString(content) > MaxNoteLength {
+		return "", fmt.Errorf("notes can't be longer than %d characters", MaxNoteLength)
+	}
+
+	return content, nil
+}
+
+// SetElementNote saves a user's note on an element. Saving an empty note removes it.
+func (m *ACSModel) SetElementNote(ctx context.Context, userID int32, elementID int32, content string) error {
+	content, err := validateNote(content)
+	if err != nil {
+		return err
+	}
+
+	if content == "" {
+		err := m.q.DeleteElementNote(ctx, queries.DeleteElementNoteParams{UserID: userID, ElementID: elementID})
+		if err != nil {
+			return fmt.Errorf("failed to remove note on element %d: %v", elementID, err)
+		}
+
+		return nil
+	}
+
+	err = m.q.UpsertElementNote(ctx, queries.UpsertElementNoteParams{
+		UserID:    userID,
+		ElementID: elementID,
+		Content:   content,
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return newNotFoundError("element", elementID, err)
+		}
+
+		return fmt.Errorf("failed to save note on element %d: %v", elementID, err)
+	}
+
+	m.logger.InfoContext(ctx, "Saved element note.", "userID", userID, "elementID", elementID)
+
+	return nil
+}
+
+// SetSubElementNote saves a user's note on the sub-element at a particular position within an
+// element. Saving an empty note removes it.
+func (m *ACSModel) SetSubElementNote(
+	ctx context.Context,
+	userID int32,
+	elementID int32,
+	order int32,
+	content string,
+) error {
+	content, err := validateNote(content)
+	if err != nil {
+		return err
+	}
+
+	if content == "" {
+		err := m.q.DeleteSubElementNote(ctx, queries.DeleteSubElementNoteParams{
+			UserID:          userID,
+			ElementID:       elementID,
+			SubElementOrder: order,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove note on sub-element %d of element %d: %v", order, elementID, err)
+		}
+
+		return nil
+	}
+
+	saved, err := m.q.UpsertSubElementNote(ctx, queries.UpsertSubElementNoteParams{
+		UserID:          userID,
+		Content:         content,
+		ElementID:       elementID,
+		SubElementOrder: order,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save note on sub-element %d of element %d: %v", order, elementID, err)
+	}
+
+	if saved == 0 {
+		return newNotFoundError("sub-element", fmt.Sprintf("%d/%d", elementID, order), pgx.ErrNoRows)
+	}
+
+	m.logger.InfoContext(ctx, "Saved sub-element note.", "userID", userID, "elementID", elementID, "order", order)
+
+	return nil
+}
+
+// clearTaskNotes removes the notes from a task's elements and sub-elements so the task can be shown
+// to someone other than the notes' author.
+func clearTaskNotes(task *Task) {
+	groups := [][]TaskElement{
+		task.KnowledgeElements,
+		task.RiskManagementElements,
+		task.SkillElements,
+		task.PTSElements,
+	}
+
+	for _, elements := range groups {
+		for i := range elements {
+			elements[i].Note = nil
+
+			for j := range elements[i].SubElements {
+				elements[i].SubElements[j].Note = nil
+			}
+		}
+	}
+}
+
+// attachNotes fills in a user's notes on a set of elements and their sub-elements.
+func (m *ACSModel) attachNotes(ctx context.Context, userID int32, elements []TaskElement) error {
+	if len(elements) == 0 {
+		return nil
+	}
+
+	elementIDs := make([]int32, len(elements))
+	for i, e := range elements {
+		elementIDs[i] = e.ID
+	}
+
+	notes, err := m.q.ListElementNotesByElementIDs(ctx, queries.ListElementNotesByElementIDsParams{
+		UserID:     userID,
+		ElementIds: elementIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list element notes: %v", err)
+	}
+
+	subElementNotes, err := m.q.ListSubElementNotesByElementIDs(ctx, queries.ListSubElementNotesByElementIDsParams{
+		UserID:     userID,
+		ElementIds: elementIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list sub-element notes: %v", err)
+	}
+
+	notesByElement := make(map[int32]Note, len(notes))
+	for _, n := range notes {
+		notesByElement[n.ElementID] = Note{Content: n.Content, UpdatedAt: n.UpdatedAt.Time}
+	}
+
+	notesBySubElement := make(map[subElementNoteKey]Note, len(subElementNotes))
+	for _, n := range subElementNotes {
+		key := subElementNoteKey{n.ElementID, n.SubElementOrder}
+		notesBySubElement[key] = Note{Content: n.Content, UpdatedAt: n.UpdatedAt.Time}
+	}
+
+	for i := range elements {
+		if note, ok := notesByElement[elements[i].ID]; ok {
+			elements[i].Note = &note
+		}
+
+		for j := range elements[i].SubElements {
+			key := subElementNoteKey{elements[i].ID, elements[i].SubElements[j].Order}
+			if note, ok := notesBySubElement[key]; ok {
+				elements[i].SubElements[j].Note = &note
+			}
+		}
+	}
+
+	return nil
+}
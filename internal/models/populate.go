@@ -5,17 +5,55 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/cdriehuys/flight-school/internal/models/queries"
 	"github.com/jackc/pgx/v5"
 )
 
 type ExternalACS struct {
-	ID    string         `json:"id"`
+	// Schema references the JSON schema the document follows. It is ignored when loading.
+	Schema string `json:"$schema,omitempty"`
+
+	ID string `json:"id"`
+
+	// Edition distinguishes revisions of the same ACS, e.g. "6C" for FAA-S-ACS-6C. It may be
+	// omitted for an ACS that is only loaded in one edition.
+	Edition string `json:"edition,omitempty"`
+
+	// Kind tells ACS documents apart from the Practical Test Standards they replaced. Documents
+	// without a kind are an ACS.
+	Kind ACSKind `json:"kind,omitempty"`
+
 	Name  string         `json:"name"`
 	Areas []ExternalArea `json:"areas"`
 }
 
+// EditionID returns the ID the document is stored under.
+func (a ExternalACS) EditionID() string {
+	return EditionID(a.ID, a.Edition)
+}
+
+// DocumentKind returns the kind of the document, treating an empty kind as an ACS.
+func (a ExternalACS) DocumentKind() ACSKind {
+	if a.Kind == "" {
+		return ACSKindACS
+	}
+
+	return a.Kind
+}
+
+// EditionID combines an ACS code and edition into the ID of the edition, e.g. "PA-6C". An ACS
+// without an edition is stored under its code.
+func EditionID(code string, edition string) string {
+	if edition == "" {
+		return code
+	}
+
+	return code + "-" + edition
+}
+
 type ExternalArea struct {
 	ID    string         `json:"id"`
 	Name  string         `json:"name"`
@@ -23,32 +61,156 @@ type ExternalArea struct {
 }
 
 type ExternalTask struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// AppliesTo lists the class ratings the task applies to. Tasks without any apply to every
+	// class.
+	AppliesTo []string `json:"appliesTo,omitempty"`
+
 	Objective string `json:"objective"`
-	Note      string `json:"note"`
+	Note      string `json:"note,omitempty"`
+
+	References []string `json:"references,omitempty"`
 
-	References []string `json:"references"`
+	Knowledge      []ExternalElement `json:"knowledge,omitempty"`
+	RiskManagement []ExternalElement `json:"riskManagement,omitempty"`
+	Skills         []ExternalElement `json:"skills,omitempty"`
 
-	Knowledge      []ExternalElement `json:"knowledge"`
-	RiskManagement []ExternalElement `json:"riskManagement"`
-	Skills         []ExternalElement `json:"skills"`
+	// PTSElements are the elements of a task in a PTS document, which aren't split into
+	// knowledge, risk management, and skills.
+	PTSElements []ExternalElement `json:"elements,omitempty"`
 }
 
 type ExternalElement struct {
-	ID          int32                `json:"id"`
-	Content     string               `json:"content"`
-	SubElements []ExternalSubElement `json:"subElements"`
+	ID      int32  `json:"id"`
+	Content string `json:"content"`
+
+	// AppliesTo lists the class ratings the element applies to. Elements without any apply to
+	// every class the task applies to.
+	AppliesTo []string `json:"appliesTo,omitempty"`
+
+	SubElements []ExternalSubElement `json:"subElements,omitempty"`
 }
 
 type ExternalSubElement struct {
 	Content string `json:"content"`
+
+	// AppliesTo lists the class ratings the sub-element applies to, like an element's classes.
+	AppliesTo []string `json:"appliesTo,omitempty"`
+}
+
+// PopulateOptions controls how user data is handled when an ACS document is loaded over an
+// existing copy.
+type PopulateOptions struct {
+	// Remap moves user data from elements in the existing ACS to elements in the new document. Keys
+	// are the old FullPublicID and values are the new one. Entire tasks may be remapped by using
+	// task IDs such as "PA.I.A", in which case each element moves to the element with the same
+	// type and number in the new task.
+	Remap map[string]string
+
+	// AllowDataLoss permits removing elements that carry user data, or remapping onto elements
+	// whose own user data isn't remapped elsewhere. Without it, populating a document that would
+	// discard such data fails.
+	AllowDataLoss bool
+
+	// DryRun rolls back all changes once the document is loaded. Elements with user data that would
+	// be removed are reported instead of causing an error.
+	DryRun bool
+}
+
+// PopulateReport describes the changes made by populating an ACS.
+type PopulateReport struct {
+	ACS    string  `json:"acs"`
+	DryRun bool    `json:"dryRun"`
+	Diff   ACSDiff `json:"diff"`
+
+	// DataLoss lists the elements whose user data was discarded, either because they were removed
+	// or because user data was remapped onto them in its place.
+	DataLoss []ElementUserData `json:"dataLoss"`
+
+	// ChangedElements lists the elements with user data whose content changed without being
+	// remapped. Their user data stays with their public ID, so it should be checked in case the
+	// requirement it was recorded against moved to a different element.
+	ChangedElements []ElementUserData `json:"changedElements"`
 }
 
-func (m *ACSModel) PopulateACS(ctx context.Context, acs ExternalACS) error {
+// LostVotes returns the number of current confidence votes that were discarded.
+func (r PopulateReport) LostVotes() int {
+	votes := 0
+	for _, element := range r.DataLoss {
+		votes += element.Votes
+	}
+
+	return votes
+}
+
+// DataLossError is returned when populating an ACS would discard user data, either by deleting
+// elements or by remapping onto them.
+type DataLossError struct {
+	Elements []ElementUserData
+}
+
+func (e *DataLossError) Error() string {
+	ids := make([]string, len(e.Elements))
+	for i, element := range e.Elements {
+		ids[i] = element.FullPublicID
+	}
+
+	return fmt.Sprintf(
+		"refusing to discard user data from %d elements (%s); remap it or allow data loss",
+		len(e.Elements),
+		strings.Join(ids, ", "),
+	)
+}
+
+// ElementUserData summarizes the user data attached to an element.
+type ElementUserData struct {
+	FullPublicID string `json:"id"`
+
+	// Votes is the number of users with a current confidence vote for the element.
+	Votes int `json:"votes"`
+
+	// HistoryEvents is the total number of confidence events recorded for the element.
+	HistoryEvents int `json:"historyEvents"`
+
+	// Notes is the number of personal notes on the element and its sub-elements.
+	Notes int `json:"notes"`
+
+	// SignOffs is the number of students an instructor has signed off on the element.
+	SignOffs int `json:"signOffs"`
+
+	// Missed is the number of users who missed the element on a knowledge test.
+	Missed int `json:"missed"`
+
+	// ExamGrades is the number of mock oral exams that graded the element.
+	ExamGrades int `json:"examGrades"`
+}
+
+// populateState tracks the rows written while populating an ACS. Areas, tasks, and elements that
+// are not written are only removed once the whole document is loaded so that user data can be
+// moved off of them first.
+type populateState struct {
+	areas    []int32
+	tasks    map[int32][]int32
+	elements map[int32][]int32
+}
+
+func (s populateState) allElements() []int32 {
+	all := make([]int32, 0)
+	for _, elements := range s.elements {
+		all = append(all, elements...)
+	}
+
+	return all
+}
+
+// PopulateACS loads an ACS document into the database, replacing any existing copy of the same
+// edition. The first edition loaded for an ACS becomes its current edition.
+func (m *ACSModel) PopulateACS(ctx context.Context, acs ExternalACS, opts PopulateOptions) (PopulateReport, error) {
 	tx, err := m.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to start transaction: %v", err)
+		return PopulateReport{}, fmt.Errorf("failed to start transaction: %v", err)
 	}
 
 	defer func() {
@@ -58,30 +220,190 @@ func (m *ACSModel) PopulateACS(ctx context.Context, acs ExternalACS) error {
 	}()
 
 	q := queries.New(tx)
+
+	before, err := exportACS(ctx, q, acs.EditionID())
+	if err != nil {
+		return PopulateReport{}, fmt.Errorf("failed to read existing ACS: %v", err)
+	}
+
 	acsModel, err := q.UpsertACS(ctx, queries.UpsertACSParams{
-		ID:   acs.ID,
-		Name: acs.Name,
+		ID:      acs.EditionID(),
+		Code:    acs.ID,
+		Edition: acs.Edition,
+		Name:    acs.Name,
+		Kind:    queries.AcsKind(acs.DocumentKind()),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to insert ACS: %v", err)
+		return PopulateReport{}, fmt.Errorf("failed to insert ACS: %v", err)
 	}
 
 	logger := m.logger.With("acs", acsModel.ID)
-	logger.InfoContext(ctx, "Updated ACS")
+	logger.DebugContext(ctx, "Updated ACS")
 
-	knownAreas := make([]int32, len(acs.Areas))
+	// Element IDs have to be resolved before anything is written because an element's row may be
+	// reused for different content if elements are renumbered.
+	previousElements, err := listElementIDsByFullPublicID(ctx, q, acsModel.ID)
+	if err != nil {
+		return PopulateReport{}, err
+	}
+
+	state := populateState{
+		areas:    make([]int32, len(acs.Areas)),
+		tasks:    make(map[int32][]int32),
+		elements: make(map[int32][]int32),
+	}
 	for i, area := range acs.Areas {
-		areaModel, err := m.upsertArea(ctx, logger, q, acsModel.ID, int32(i), area)
+		areaModel, err := m.upsertArea(ctx, logger, q, &state, acsModel.ID, int32(i), area)
 		if err != nil {
-			return fmt.Errorf("failed to update area %s: %v", area.ID, err)
+			return PopulateReport{}, fmt.Errorf("failed to update area %s: %v", area.ID, err)
 		}
 
-		knownAreas[i] = areaModel.ID
+		state.areas[i] = areaModel.ID
+	}
+
+	currentElements, err := listElementIDsByFullPublicID(ctx, q, acsModel.ID)
+	if err != nil {
+		return PopulateReport{}, err
+	}
+
+	oldIDs, remapped, err := resolveRemap(opts.Remap, previousElements, currentElements)
+	if err != nil {
+		return PopulateReport{}, fmt.Errorf("failed to remap user data: %v", err)
+	}
+
+	// An element that receives remapped user data without giving its own away would mix the two,
+	// so its own data is discarded first.
+	replaced, err := m.clearRemapTargets(ctx, logger, q, oldIDs, remapped)
+	if err != nil {
+		return PopulateReport{}, err
+	}
+
+	if err := m.remapUserData(ctx, logger, q, oldIDs, remapped); err != nil {
+		return PopulateReport{}, fmt.Errorf("failed to remap user data: %v", err)
+	}
+
+	removed, err := m.checkDataLoss(ctx, q, currentElements, state.allElements())
+	if err != nil {
+		return PopulateReport{}, err
+	}
+
+	dataLoss := append(replaced, removed...)
+	if len(dataLoss) > 0 {
+		if !opts.AllowDataLoss && !opts.DryRun {
+			return PopulateReport{}, &DataLossError{Elements: dataLoss}
+		}
+
+		for _, element := range dataLoss {
+			logger.WarnContext(
+				ctx,
+				"Discarding user data from element.",
+				"element", element.FullPublicID,
+				"votes", element.Votes,
+				"historyEvents", element.HistoryEvents,
+				"notes", element.Notes,
+				"signOffs", element.SignOffs,
+				"missed", element.Missed,
+				"examGrades", element.ExamGrades,
+			)
+		}
+	}
+
+	if err := m.clearUnknownRows(ctx, logger, q, acsModel.ID, state); err != nil {
+		return PopulateReport{}, err
+	}
+
+	after, err := exportACS(ctx, q, acsModel.ID)
+	if err != nil {
+		return PopulateReport{}, fmt.Errorf("failed to read updated ACS: %v", err)
+	}
+
+	report := PopulateReport{
+		ACS:      acsModel.ID,
+		DryRun:   opts.DryRun,
+		Diff:     DiffACS(before, after),
+		DataLoss: dataLoss,
+	}
+
+	report.ChangedElements, err = m.checkChangedElements(ctx, q, report.Diff, currentElements, remapped)
+	if err != nil {
+		return PopulateReport{}, err
+	}
+
+	for _, element := range report.ChangedElements {
+		logger.WarnContext(
+			ctx,
+			"Changed content of element with user data.",
+			"element", element.FullPublicID,
+			"votes", element.Votes,
+			"notes", element.Notes,
+		)
+	}
+
+	if opts.DryRun {
+		logger.InfoContext(ctx, "Dry run complete; rolling back changes.", "changes", len(report.Diff.Changes))
+
+		return report, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return PopulateReport{}, fmt.Errorf("failed to commit ACS update: %v", err)
+	}
+
+	logger.InfoContext(
+		ctx,
+		"Populated ACS.",
+		"added", report.Diff.Count(ACSChangeAdded),
+		"changed", report.Diff.Count(ACSChangeChanged),
+		"removed", report.Diff.Count(ACSChangeRemoved),
+	)
+
+	return report, nil
+}
+
+// clearUnknownRows removes the areas, tasks, and elements that were not part of the populated
+// document.
+func (m *ACSModel) clearUnknownRows(
+	ctx context.Context,
+	logger *slog.Logger,
+	q *queries.Queries,
+	acsID string,
+	state populateState,
+) error {
+	for taskID, known := range state.elements {
+		unknownElementCount, err := q.ClearUnknownTaskElements(ctx, queries.ClearUnknownTaskElementsParams{
+			TaskID:   taskID,
+			KnownIds: known,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove unknown elements: %v", err)
+		}
+
+		if unknownElementCount == 0 {
+			logger.DebugContext(ctx, "No extra task elements to remove", "taskID", taskID)
+		} else {
+			logger.InfoContext(ctx, "Removed extra task elements", "taskID", taskID, "count", unknownElementCount)
+		}
+	}
+
+	for areaID, known := range state.tasks {
+		unknownTaskCount, err := q.ClearUnknownTasks(ctx, queries.ClearUnknownTasksParams{
+			AreaID:   areaID,
+			KnownIds: known,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to clear unknown tasks: %v", err)
+		}
+
+		if unknownTaskCount == 0 {
+			logger.DebugContext(ctx, "No extra tasks deleted.", "areaID", areaID)
+		} else {
+			logger.InfoContext(ctx, "Extra tasks deleted", "areaID", areaID, "count", unknownTaskCount)
+		}
 	}
 
 	unknownAreaCount, err := q.ClearUnknownAreas(ctx, queries.ClearUnknownAreasParams{
-		AcsID:    acsModel.ID,
-		KnownIds: knownAreas,
+		AcsID:    acsID,
+		KnownIds: state.areas,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to remove unknown areas: %v", err)
@@ -93,17 +415,434 @@ func (m *ACSModel) PopulateACS(ctx context.Context, acs ExternalACS) error {
 		logger.InfoContext(ctx, "Removed unknown areas.", "count", unknownAreaCount)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit ACS update: %v", err)
+	return nil
+}
+
+func listElementIDsByFullPublicID(ctx context.Context, q *queries.Queries, acsID string) (map[string]int32, error) {
+	rows, err := q.ListElementIDsByACS(ctx, acsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list elements for ACS %s: %v", acsID, err)
+	}
+
+	elements := make(map[string]int32, len(rows))
+	for _, row := range rows {
+		elements[row.FullPublicID] = row.ID
+	}
+
+	return elements, nil
+}
+
+// resolveRemap converts a remapping of public IDs into pairs of element IDs. Task-level entries are
+// expanded to each element within the old task.
+func resolveRemap(remap map[string]string, previous map[string]int32, current map[string]int32) ([]int32, []int32, error) {
+	pairs := make(map[string]string)
+	for oldID, newID := range remap {
+		if _, ok := previous[oldID]; ok {
+			pairs[oldID] = newID
+			continue
+		}
+
+		// Not an element, so try to expand it as a task.
+		prefix := oldID + "."
+		expanded := false
+		for elementID := range previous {
+			if suffix, ok := strings.CutPrefix(elementID, prefix); ok && !strings.Contains(suffix, ".") {
+				pairs[elementID] = newID + "." + suffix
+				expanded = true
+			}
+		}
+
+		if !expanded {
+			return nil, nil, fmt.Errorf("remapped element or task %s does not exist", oldID)
+		}
 	}
 
+	oldIDs := make([]string, 0, len(pairs))
+	for oldID := range pairs {
+		oldIDs = append(oldIDs, oldID)
+	}
+
+	slices.Sort(oldIDs)
+
+	oldElements := make([]int32, 0, len(pairs))
+	newElements := make([]int32, 0, len(pairs))
+	for _, oldID := range oldIDs {
+		newID := pairs[oldID]
+		newElement, ok := current[newID]
+		if !ok {
+			return nil, nil, fmt.Errorf("remap target %s for %s does not exist in the new document", newID, oldID)
+		}
+
+		oldElements = append(oldElements, previous[oldID])
+		newElements = append(newElements, newElement)
+	}
+
+	return oldElements, newElements, nil
+}
+
+// remapOverwrites finds the remap targets that are not also remapped themselves. Their existing
+// user data would be merged with the data moved onto them.
+func remapOverwrites(oldIDs []int32, newIDs []int32) []int32 {
+	overwritten := make([]int32, 0)
+	for _, id := range newIDs {
+		if !slices.Contains(oldIDs, id) && !slices.Contains(overwritten, id) {
+			overwritten = append(overwritten, id)
+		}
+	}
+
+	return overwritten
+}
+
+// clearRemapTargets removes the existing user data from remap targets that are not remapped
+// themselves. The user data that was removed is returned.
+func (m *ACSModel) clearRemapTargets(
+	ctx context.Context,
+	logger *slog.Logger,
+	q *queries.Queries,
+	oldIDs []int32,
+	newIDs []int32,
+) ([]ElementUserData, error) {
+	overwritten := remapOverwrites(oldIDs, newIDs)
+
+	replaced, err := listElementUserData(ctx, q, overwritten)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for user data on remap targets: %v", err)
+	}
+
+	if len(replaced) == 0 {
+		return replaced, nil
+	}
+
+	if err := q.DeleteConfidenceEventsByElementIDs(ctx, overwritten); err != nil {
+		return nil, fmt.Errorf("failed to remove confidence history from remap targets: %v", err)
+	}
+
+	if err := q.DeleteElementReviewsByElementIDs(ctx, overwritten); err != nil {
+		return nil, fmt.Errorf("failed to remove review schedules from remap targets: %v", err)
+	}
+
+	if err := q.DeleteElementNotesByElementIDs(ctx, overwritten); err != nil {
+		return nil, fmt.Errorf("failed to remove notes from remap targets: %v", err)
+	}
+
+	if err := q.DeleteSubElementNotesByElementIDs(ctx, overwritten); err != nil {
+		return nil, fmt.Errorf("failed to remove sub-element notes from remap targets: %v", err)
+	}
+
+	if err := q.DeleteElementSignOffsByElementIDs(ctx, overwritten); err != nil {
+		return nil, fmt.Errorf("failed to remove sign-offs from remap targets: %v", err)
+	}
+
+	if err := q.DeleteMissedElementsByElementIDs(ctx, overwritten); err != nil {
+		return nil, fmt.Errorf("failed to remove missed elements from remap targets: %v", err)
+	}
+
+	if err := q.DeleteExamElementGradesByElementIDs(ctx, overwritten); err != nil {
+		return nil, fmt.Errorf("failed to remove exam grades from remap targets: %v", err)
+	}
+
+	logger.InfoContext(ctx, "Cleared user data from remap targets.", "elements", len(replaced))
+
+	return replaced, nil
+}
+
+// remapUserData moves user data from each of the old elements to the new element at the same
+// index.
+func (m *ACSModel) remapUserData(
+	ctx context.Context,
+	logger *slog.Logger,
+	q *queries.Queries,
+	oldIDs []int32,
+	newIDs []int32,
+) error {
+	if len(oldIDs) == 0 {
+		return nil
+	}
+
+	// Events have no uniqueness constraints, so they can be moved in a single statement even if
+	// elements swap places.
+	eventCount, err := q.RemapConfidenceEvents(ctx, queries.RemapConfidenceEventsParams{
+		OldIds: oldIDs,
+		NewIds: newIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move confidence history: %v", err)
+	}
+
+	// Review schedules are unique per user and element, so they are removed before being written
+	// back to their new elements.
+	reviews, err := q.ListElementReviewsByElementIDs(ctx, oldIDs)
+	if err != nil {
+		return fmt.Errorf("failed to list review schedules: %v", err)
+	}
+
+	if err := q.DeleteElementReviewsByElementIDs(ctx, oldIDs); err != nil {
+		return fmt.Errorf("failed to remove old review schedules: %v", err)
+	}
+
+	for _, review := range reviews {
+		err := q.UpsertElementReview(ctx, queries.UpsertElementReviewParams{
+			UserID:       review.UserID,
+			ElementID:    newIDs[slices.Index(oldIDs, review.ElementID)],
+			Repetitions:  review.Repetitions,
+			IntervalDays: review.IntervalDays,
+			EaseFactor:   review.EaseFactor,
+			RatedAt:      review.RatedAt,
+			DueAt:        review.DueAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move review schedule: %v", err)
+		}
+	}
+
+	noteCount, err := moveNotes(ctx, q, oldIDs, newIDs)
+	if err != nil {
+		return err
+	}
+
+	signOffCount, err := moveSignOffs(ctx, q, oldIDs, newIDs)
+	if err != nil {
+		return err
+	}
+
+	missedCount, err := moveMissedElements(ctx, q, oldIDs, newIDs)
+	if err != nil {
+		return err
+	}
+
+	gradeCount, err := moveExamGrades(ctx, q, oldIDs, newIDs)
+	if err != nil {
+		return err
+	}
+
+	logger.InfoContext(
+		ctx,
+		"Remapped user data.",
+		"elements", len(oldIDs),
+		"confidenceEvents", eventCount,
+		"reviews", len(reviews),
+		"notes", noteCount,
+		"signOffs", signOffCount,
+		"missed", missedCount,
+		"examGrades", gradeCount,
+	)
+
 	return nil
 }
 
+// moveNotes moves personal notes from one set of elements to another. Like review schedules,
+// notes are unique per user and element, so they are removed before being written back. A note
+// moved onto an element that already has one is appended to it.
+func moveNotes(ctx context.Context, q *queries.Queries, oldIDs []int32, newIDs []int32) (int, error) {
+	notes, err := q.ListElementNotesForRemap(ctx, oldIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list notes: %v", err)
+	}
+
+	subElementNotes, err := q.ListSubElementNotesForRemap(ctx, oldIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sub-element notes: %v", err)
+	}
+
+	if err := q.DeleteElementNotesByElementIDs(ctx, oldIDs); err != nil {
+		return 0, fmt.Errorf("failed to remove old notes: %v", err)
+	}
+
+	if err := q.DeleteSubElementNotesByElementIDs(ctx, oldIDs); err != nil {
+		return 0, fmt.Errorf("failed to remove old sub-element notes: %v", err)
+	}
+
+	for _, note := range notes {
+		err := q.MergeElementNote(ctx, queries.MergeElementNoteParams{
+			UserID:    note.UserID,
+			ElementID: newIDs[slices.Index(oldIDs, note.ElementID)],
+			Content:   note.Content,
+			UpdatedAt: note.UpdatedAt,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to move note: %v", err)
+		}
+	}
+
+	for _, note := range subElementNotes {
+		err := q.MergeSubElementNote(ctx, queries.MergeSubElementNoteParams{
+			UserID:          note.UserID,
+			ElementID:       newIDs[slices.Index(oldIDs, note.ElementID)],
+			SubElementOrder: note.SubElementOrder,
+			Content:         note.Content,
+			UpdatedAt:       note.UpdatedAt,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to move sub-element note: %v", err)
+		}
+	}
+
+	return len(notes) + len(subElementNotes), nil
+}
+
+// moveSignOffs moves instructor sign-offs from one set of elements to another. A sign-off moved
+// onto an element the student is already signed off on is dropped in favor of the existing one.
+func moveSignOffs(ctx context.Context, q *queries.Queries, oldIDs []int32, newIDs []int32) (int, error) {
+	signOffs, err := q.ListElementSignOffsByElementIDs(ctx, oldIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sign-offs: %v", err)
+	}
+
+	if err := q.DeleteElementSignOffsByElementIDs(ctx, oldIDs); err != nil {
+		return 0, fmt.Errorf("failed to remove old sign-offs: %v", err)
+	}
+
+	for _, signOff := range signOffs {
+		err := q.MergeElementSignOff(ctx, queries.MergeElementSignOffParams{
+			StudentID:    signOff.StudentID,
+			ElementID:    newIDs[slices.Index(oldIDs, signOff.ElementID)],
+			InstructorID: signOff.InstructorID,
+			SignedOffAt:  signOff.SignedOffAt,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to move sign-off: %v", err)
+		}
+	}
+
+	return len(signOffs), nil
+}
+
+// moveMissedElements moves the elements users missed on knowledge tests.
+func moveMissedElements(ctx context.Context, q *queries.Queries, oldIDs []int32, newIDs []int32) (int, error) {
+	missed, err := q.ListMissedElementsByElementIDs(ctx, oldIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list missed elements: %v", err)
+	}
+
+	if err := q.DeleteMissedElementsByElementIDs(ctx, oldIDs); err != nil {
+		return 0, fmt.Errorf("failed to remove old missed elements: %v", err)
+	}
+
+	for _, m := range missed {
+		err := q.MergeMissedElement(ctx, queries.MergeMissedElementParams{
+			UserID:    m.UserID,
+			ElementID: newIDs[slices.Index(oldIDs, m.ElementID)],
+			CreatedAt: m.CreatedAt,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to move missed element: %v", err)
+		}
+	}
+
+	return len(missed), nil
+}
+
+// moveExamGrades moves the grades given on mock oral exams. If an exam already graded the new
+// element, the more recent grade is kept.
+func moveExamGrades(ctx context.Context, q *queries.Queries, oldIDs []int32, newIDs []int32) (int, error) {
+	grades, err := q.ListExamElementGradesByElementIDs(ctx, oldIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list exam grades: %v", err)
+	}
+
+	if err := q.DeleteExamElementGradesByElementIDs(ctx, oldIDs); err != nil {
+		return 0, fmt.Errorf("failed to remove old exam grades: %v", err)
+	}
+
+	for _, grade := range grades {
+		err := q.MergeExamElementGrade(ctx, queries.MergeExamElementGradeParams{
+			ExamID:    grade.ExamID,
+			ElementID: newIDs[slices.Index(oldIDs, grade.ElementID)],
+			Grade:     grade.Grade,
+			GradedAt:  grade.GradedAt,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to move exam grade: %v", err)
+		}
+	}
+
+	return len(grades), nil
+}
+
+// checkDataLoss finds elements that are about to be removed while they still carry user data.
+// Every element of the ACS is in the current elements until unknown rows are cleared, so the ones
+// that weren't written by the document are the ones being removed.
+func (m *ACSModel) checkDataLoss(
+	ctx context.Context,
+	q *queries.Queries,
+	current map[string]int32,
+	knownElements []int32,
+) ([]ElementUserData, error) {
+	unknown := make([]int32, 0)
+	for _, id := range current {
+		if !slices.Contains(knownElements, id) {
+			unknown = append(unknown, id)
+		}
+	}
+
+	elements, err := listElementUserData(ctx, q, unknown)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for user data on removed elements: %v", err)
+	}
+
+	return elements, nil
+}
+
+// checkChangedElements finds elements that kept their public ID while their content changed, and
+// still carry user data that wasn't moved onto them by a remap.
+func (m *ACSModel) checkChangedElements(
+	ctx context.Context,
+	q *queries.Queries,
+	diff ACSDiff,
+	current map[string]int32,
+	remapped []int32,
+) ([]ElementUserData, error) {
+	changed := make([]int32, 0)
+	for _, change := range diff.Changes {
+		if change.Kind != ACSChangeChanged || change.Type != "element" || change.Field != "content" {
+			continue
+		}
+
+		if id, ok := current[change.ID]; ok && !slices.Contains(remapped, id) {
+			changed = append(changed, id)
+		}
+	}
+
+	elements, err := listElementUserData(ctx, q, changed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for user data on changed elements: %v", err)
+	}
+
+	return elements, nil
+}
+
+// listElementUserData summarizes the user data on each of the given elements that has any.
+func listElementUserData(ctx context.Context, q *queries.Queries, elementIDs []int32) ([]ElementUserData, error) {
+	if len(elementIDs) == 0 {
+		return []ElementUserData{}, nil
+	}
+
+	rows, err := q.ListElementUserData(ctx, elementIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]ElementUserData, len(rows))
+	for i, row := range rows {
+		elements[i] = ElementUserData{
+			FullPublicID:  row.FullPublicID,
+			Votes:         int(row.VoteCount),
+			HistoryEvents: int(row.EventCount),
+			Notes:         int(row.NoteCount),
+			SignOffs:      int(row.SignoffCount),
+			Missed:        int(row.MissedCount),
+			ExamGrades:    int(row.GradeCount),
+		}
+	}
+
+	return elements, nil
+}
+
 func (m *ACSModel) upsertArea(
 	ctx context.Context,
 	logger *slog.Logger,
 	q *queries.Queries,
+	state *populateState,
 	acs string,
 	order int32,
 	area ExternalArea,
@@ -119,11 +858,11 @@ func (m *ACSModel) upsertArea(
 	}
 
 	logger = logger.With("area", areaModel.PublicID)
-	logger.InfoContext(ctx, "Updated ACS area")
+	logger.DebugContext(ctx, "Updated ACS area")
 
 	knownTasks := make([]int32, len(area.Tasks))
 	for i, task := range area.Tasks {
-		taskModel, err := m.upsertTask(ctx, logger, q, areaModel.ID, task)
+		taskModel, err := m.upsertTask(ctx, logger, q, state, areaModel.ID, task)
 		if err != nil {
 			return queries.AcsArea{}, fmt.Errorf("failed to update task for area: %v", err)
 		}
@@ -131,19 +870,7 @@ func (m *ACSModel) upsertArea(
 		knownTasks[i] = taskModel.ID
 	}
 
-	unknownTaskCount, err := q.ClearUnknownTasks(ctx, queries.ClearUnknownTasksParams{
-		AreaID:   areaModel.ID,
-		KnownIds: knownTasks,
-	})
-	if err != nil {
-		return queries.AcsArea{}, fmt.Errorf("failed to clear unknown tasks: %v", err)
-	}
-
-	if unknownTaskCount == 0 {
-		logger.DebugContext(ctx, "No extra tasks deleted.")
-	} else {
-		logger.InfoContext(ctx, "Extra tasks deleted", "count", unknownTaskCount)
-	}
+	state.tasks[areaModel.ID] = knownTasks
 
 	return areaModel, nil
 }
@@ -152,6 +879,7 @@ func (m *ACSModel) upsertTask(
 	ctx context.Context,
 	logger *slog.Logger,
 	q *queries.Queries,
+	state *populateState,
 	areaID int32,
 	task ExternalTask,
 ) (queries.Task, error) {
@@ -161,13 +889,14 @@ func (m *ACSModel) upsertTask(
 		Name:      task.Name,
 		Objective: task.Objective,
 		Note:      task.Note,
+		AppliesTo: task.AppliesTo,
 	})
 	if err != nil {
 		return queries.Task{}, fmt.Errorf("failed to upsert task: %v", err)
 	}
 
 	logger = logger.With("task", taskModel.PublicID)
-	logger.InfoContext(ctx, "Updated task")
+	logger.DebugContext(ctx, "Updated task")
 
 	knownReferences := make([]int32, len(task.References))
 	for i, reference := range task.References {
@@ -193,7 +922,7 @@ func (m *ACSModel) upsertTask(
 		logger.InfoContext(ctx, "Removed extra task references", "count", unknownReferenceCount)
 	}
 
-	knownElements := make([]int32, 0, len(task.Knowledge)+len(task.RiskManagement)+len(task.Skills))
+	knownElements := make([]int32, 0, len(task.Knowledge)+len(task.RiskManagement)+len(task.Skills)+len(task.PTSElements))
 	upsertElements := func(elementType TaskElementType, elements []ExternalElement) error {
 		for _, e := range elements {
 			elementModel, err := m.upsertElement(ctx, logger, q, taskModel.ID, elementType, e)
@@ -219,19 +948,11 @@ func (m *ACSModel) upsertTask(
 		return queries.Task{}, err
 	}
 
-	unknownElementCount, err := q.ClearUnknownTaskElements(ctx, queries.ClearUnknownTaskElementsParams{
-		TaskID:   taskModel.ID,
-		KnownIds: knownElements,
-	})
-	if err != nil {
-		return queries.Task{}, fmt.Errorf("failed to remove unknown elements: %v", err)
+	if err := upsertElements(TaskElementTypePTS, task.PTSElements); err != nil {
+		return queries.Task{}, err
 	}
 
-	if unknownElementCount == 0 {
-		logger.DebugContext(ctx, "No extra task elements to remove")
-	} else {
-		logger.InfoContext(ctx, "Removed extra task elements", "count", unknownElementCount)
-	}
+	state.elements[taskModel.ID] = knownElements
 
 	return taskModel, nil
 }
@@ -253,7 +974,7 @@ func (m *ACSModel) upsertTaskReference(
 		return queries.TaskReference{}, fmt.Errorf("failed to update task reference: %v", err)
 	}
 
-	logger.InfoContext(ctx, "Updated task reference", "reference", reference)
+	logger.DebugContext(ctx, "Updated task reference", "reference", reference)
 
 	return referenceModel, nil
 }
@@ -267,17 +988,18 @@ func (m *ACSModel) upsertElement(
 	element ExternalElement,
 ) (queries.AcsElement, error) {
 	elementModel, err := q.UpsertTaskElement(ctx, queries.UpsertTaskElementParams{
-		TaskID:   taskID,
-		Type:     elementTypeModel(elementType),
-		PublicID: element.ID,
-		Content:  element.Content,
+		TaskID:    taskID,
+		Type:      elementTypeModel(elementType),
+		PublicID:  element.ID,
+		Content:   element.Content,
+		AppliesTo: element.AppliesTo,
 	})
 	if err != nil {
 		return queries.AcsElement{}, fmt.Errorf("failed to update task element: %v", err)
 	}
 
 	logger = logger.With("element", fmt.Sprintf("%s%d", elementType, element.ID))
-	logger.InfoContext(ctx, "Updated task element")
+	logger.DebugContext(ctx, "Updated task element")
 
 	knownSubElements := make([]int32, len(element.SubElements))
 	for i, s := range element.SubElements {
@@ -316,6 +1038,9 @@ func elementTypeModel(t TaskElementType) queries.AcsElementType {
 
 	case TaskElementTypeSkills:
 		return queries.AcsElementTypeS
+
+	case TaskElementTypePTS:
+		return queries.AcsElementTypeE
 	}
 
 	panic(fmt.Sprintf("Unknown task element type %s", t))
@@ -335,13 +1060,14 @@ func (m *ACSModel) upsertSubElement(
 		ElementID: elementID,
 		Order:     order,
 		Content:   subElement.Content,
+		AppliesTo: subElement.AppliesTo,
 	})
 	if err != nil {
 		return queries.AcsSubelement{}, fmt.Errorf("failed to update sub-element: %v", err)
 	}
 
 	logger = logger.With("subElement", subElementAlphabet[order])
-	logger.InfoContext(ctx, "Updated sub-element")
+	logger.DebugContext(ctx, "Updated sub-element")
 
 	return subElementModel, nil
 }
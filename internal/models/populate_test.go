@@ -0,0 +1,75 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRemapOverwrites(t *testing.T) {
+	previous := map[string]int32{
+		"PA.I.A.K1": 1,
+		"PA.I.A.K2": 2,
+		"PA.I.A.K3": 3,
+		"PA.I.B.K1": 4,
+	}
+
+	testCases := []struct {
+		name  string
+		remap map[string]string
+		want  []int32
+	}{
+		{
+			name:  "no remap",
+			remap: map[string]string{},
+			want:  []int32{},
+		},
+		{
+			name:  "onto element with its own data",
+			remap: map[string]string{"PA.I.A.K2": "PA.I.A.K1"},
+			want:  []int32{1},
+		},
+		{
+			name: "swap",
+			remap: map[string]string{
+				"PA.I.A.K1": "PA.I.A.K2",
+				"PA.I.A.K2": "PA.I.A.K1",
+			},
+			want: []int32{},
+		},
+		{
+			name: "shift",
+			remap: map[string]string{
+				"PA.I.A.K1": "PA.I.A.K2",
+				"PA.I.A.K2": "PA.I.A.K3",
+			},
+			want: []int32{3},
+		},
+		{
+			name: "merge",
+			remap: map[string]string{
+				"PA.I.A.K2": "PA.I.A.K1",
+				"PA.I.A.K3": "PA.I.A.K1",
+			},
+			want: []int32{1},
+		},
+		{
+			name:  "task",
+			remap: map[string]string{"PA.I.B": "PA.I.A"},
+			want:  []int32{1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldIDs, newIDs, err := resolveRemap(tc.remap, previous, previous)
+			if err != nil {
+				t.Fatalf("failed to resolve remap: %v", err)
+			}
+
+			got := remapOverwrites(oldIDs, newIDs)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("expected overwritten elements %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
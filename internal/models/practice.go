@@ -0,0 +1,324 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrNoQuestions is returned when a practice test would not contain any questions.
+var ErrNoQuestions = errors.New("models: no questions for practice test")
+
+// PracticeTest is a sample of questions about an ACS or one of its areas.
+type PracticeTest struct {
+	ID      int32
+	ACS     string
+	ACSName string
+
+	// Area is only set for tests limited to a single area of operation.
+	Area *AreaOfOperation
+
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+
+	Questions []PracticeQuestion
+}
+
+// Scope returns the full public ID of the ACS or area the test covers.
+func (t PracticeTest) Scope() string {
+	if t.Area != nil {
+		return t.Area.FullID()
+	}
+
+	return t.ACS
+}
+
+// CorrectCount returns the number of questions answered correctly.
+func (t PracticeTest) CorrectCount() int {
+	count := 0
+	for _, q := range t.Questions {
+		if q.Correct {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Question is a multiple choice question from the question bank.
+type Question struct {
+	ID          int32
+	PublicID    string
+	Prompt      string
+	Choices     []QuestionChoice
+	Explanation string
+
+	// Answer is the position of the correct choice.
+	Answer int32
+
+	// Elements lists the codes of the elements the question tests.
+	Elements []string
+}
+
+// AnswerLetter returns the letter of the correct choice.
+func (q Question) AnswerLetter() string {
+	return choiceLetter(q.Answer)
+}
+
+// ParseChoice converts the letter of one of the question's choices into its position.
+func (q Question) ParseChoice(letter string) (int32, bool) {
+	order, err := parseChoiceLetter(letter)
+	if err != nil || int(order) >= len(q.Choices) {
+		return 0, false
+	}
+
+	return order, true
+}
+
+type QuestionChoice struct {
+	Order   int32
+	Content string
+}
+
+// Letter returns the letter the choice is labeled with.
+func (c QuestionChoice) Letter() string {
+	return choiceLetter(c.Order)
+}
+
+// PracticeQuestion is a question asked on a practice test along with the user's answer.
+type PracticeQuestion struct {
+	Question
+	Position int
+
+	// Choice is the position of the chosen answer, or nil if the question was skipped.
+	Choice  *int32
+	Correct bool
+}
+
+// Chose indicates the user picked a particular choice.
+func (q PracticeQuestion) Chose(order int32) bool {
+	return q.Choice != nil && *q.Choice == order
+}
+
+// StartPracticeTest picks up to count questions about an ACS, or one of its areas if areaPublicID
+// is not empty, and creates a practice test from them. Questions the user hasn't answered or got
+// wrong last time are more likely to be picked.
+func (m *ACSModel) StartPracticeTest(
+	ctx context.Context,
+	userID int32,
+	acsID string,
+	areaPublicID string,
+	count int,
+) (int32, error) {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback practice test transaction.", "error", err)
+		}
+	}()
+
+	q := queries.New(tx)
+
+	var areaID pgtype.Int4
+	if areaPublicID != "" {
+		area, err := q.GetAreaByPublicID(ctx, queries.GetAreaByPublicIDParams{AcsID: acsID, PublicID: areaPublicID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, newNotFoundError("area", acsID+"."+areaPublicID, err)
+			}
+
+			return 0, fmt.Errorf("failed to retrieve area %s.%s: %v", acsID, areaPublicID, err)
+		}
+
+		areaID = pgtype.Int4{Int32: area.ID, Valid: true}
+	}
+
+	test, err := q.CreatePracticeTest(ctx, queries.CreatePracticeTestParams{
+		UserID: userID,
+		AcsID:  acsID,
+		AreaID: areaID,
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return 0, newNotFoundError("ACS", acsID, err)
+		}
+
+		return 0, fmt.Errorf("failed to create practice test: %v", err)
+	}
+
+	picked, err := q.PickPracticeTestQuestions(ctx, queries.PickPracticeTestQuestionsParams{
+		TestID:        test.ID,
+		UserID:        userID,
+		AcsID:         acsID,
+		AreaID:        areaID,
+		QuestionCount: int32(count),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to pick questions for practice test: %v", err)
+	}
+
+	if picked == 0 {
+		return 0, ErrNoQuestions
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit practice test: %v", err)
+	}
+
+	m.logger.InfoContext(ctx, "Started practice test.", "userID", userID, "testID", test.ID, "questions", picked)
+
+	return test.ID, nil
+}
+
+// GetPracticeTest retrieves one of a user's practice tests with its questions in the order they
+// are asked.
+func (m *ACSModel) GetPracticeTest(ctx context.Context, userID int32, testID int32) (PracticeTest, error) {
+	row, err := m.q.GetPracticeTest(ctx, queries.GetPracticeTestParams{ID: testID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PracticeTest{}, newNotFoundError("practice test", testID, err)
+		}
+
+		return PracticeTest{}, fmt.Errorf("failed to retrieve practice test %d: %v", testID, err)
+	}
+
+	test := PracticeTest{
+		ID:        row.PracticeTest.ID,
+		ACS:       row.PracticeTest.AcsID,
+		ACSName:   row.AcsName,
+		CreatedAt: row.PracticeTest.CreatedAt.Time,
+	}
+
+	if row.PracticeTest.AreaID.Valid {
+		test.Area = &AreaOfOperation{
+			ID:       row.PracticeTest.AreaID.Int32,
+			ACS:      row.PracticeTest.AcsID,
+			PublicID: row.AreaPublicID.String,
+			Name:     row.AreaName.String,
+		}
+	}
+
+	if row.PracticeTest.CompletedAt.Valid {
+		test.CompletedAt = &row.PracticeTest.CompletedAt.Time
+	}
+
+	questions, err := m.q.ListPracticeTestQuestions(ctx, testID)
+	if err != nil {
+		return PracticeTest{}, fmt.Errorf("failed to list questions for practice test %d: %v", testID, err)
+	}
+
+	questionIDs := make([]int32, len(questions))
+	for i, q := range questions {
+		questionIDs[i] = q.Question.ID
+	}
+
+	choices, err := m.q.ListQuestionChoicesByQuestionIDs(ctx, questionIDs)
+	if err != nil {
+		return PracticeTest{}, fmt.Errorf("failed to list choices for practice test %d: %v", testID, err)
+	}
+
+	choicesByQuestion := make(map[int32][]QuestionChoice)
+	for _, c := range choices {
+		choicesByQuestion[c.QuestionID] = append(choicesByQuestion[c.QuestionID], QuestionChoice{
+			Order:   c.Order,
+			Content: c.Content,
+		})
+	}
+
+	elements, err := m.q.ListQuestionElementsByQuestionIDs(ctx, questionIDs)
+	if err != nil {
+		return PracticeTest{}, fmt.Errorf("failed to list elements for practice test %d: %v", testID, err)
+	}
+
+	elementsByQuestion := make(map[int32][]string)
+	for _, e := range elements {
+		elementsByQuestion[e.QuestionID] = append(elementsByQuestion[e.QuestionID], e.ElementCode)
+	}
+
+	test.Questions = make([]PracticeQuestion, len(questions))
+	for i, q := range questions {
+		question := PracticeQuestion{
+			Question: Question{
+				ID:          q.Question.ID,
+				PublicID:    q.Question.PublicID,
+				Prompt:      q.Question.Prompt,
+				Choices:     choicesByQuestion[q.Question.ID],
+				Explanation: q.Question.Explanation,
+				Answer:      q.Question.Answer,
+				Elements:    elementsByQuestion[q.Question.ID],
+			},
+			Position: int(q.Position),
+			Correct:  q.Correct.Bool,
+		}
+
+		if q.Choice.Valid {
+			question.Choice = &q.Choice.Int32
+		}
+
+		test.Questions[i] = question
+	}
+
+	return test, nil
+}
+
+// SubmitPracticeTest records the user's answers, keyed by question ID, and completes the test.
+// Questions without an answer are counted as skipped. Answers to a completed test are ignored.
+func (m *ACSModel) SubmitPracticeTest(ctx context.Context, userID int32, testID int32, answers map[int32]int32) error {
+	test, err := m.GetPracticeTest(ctx, userID, testID)
+	if err != nil {
+		return err
+	}
+
+	if test.CompletedAt != nil {
+		return nil
+	}
+
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback practice test transaction.", "error", err)
+		}
+	}()
+
+	q := queries.New(tx)
+
+	for questionID, choice := range answers {
+		updated, err := q.AnswerPracticeTestQuestion(ctx, queries.AnswerPracticeTestQuestionParams{
+			Choice:     choice,
+			TestID:     testID,
+			QuestionID: questionID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to answer question %d on practice test %d: %v", questionID, testID, err)
+		}
+
+		if updated == 0 {
+			return newNotFoundError("practice test question", fmt.Sprintf("%d/%d", testID, questionID), pgx.ErrNoRows)
+		}
+	}
+
+	if err := q.CompletePracticeTest(ctx, queries.CompletePracticeTestParams{ID: testID, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to complete practice test %d: %v", testID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit practice test answers: %v", err)
+	}
+
+	m.logger.InfoContext(ctx, "Submitted practice test.", "userID", userID, "testID", testID, "answers", len(answers))
+
+	return nil
+}
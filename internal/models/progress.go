@@ -0,0 +1,486 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ProgressVersion is the version of the progress file format written by ExportProgress.
+const ProgressVersion = 1
+
+// Progress is a portable copy of a user's progress. Elements are identified by their full public
+// ID rather than a database ID, so progress can be moved to a server where the ACS documents were
+// loaded separately. Public IDs are shared by every edition of an ACS, and are imported into the
+// edition the user is studying.
+type Progress struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exportedAt"`
+	Studying   []string          `json:"studying"`
+	Elements   []ElementProgress `json:"elements"`
+}
+
+// ElementProgress holds everything a user has recorded for a single element.
+type ElementProgress struct {
+	ID string `json:"id"`
+
+	// Confidence is the current vote. It is informational when History is present, and is only
+	// imported for elements without any history.
+	Confidence string `json:"confidence,omitempty"`
+
+	History []ProgressVote  `json:"history,omitempty"`
+	Review  *ProgressReview `json:"review,omitempty"`
+
+	// MissedOnWritten is when the element was flagged from a knowledge test report.
+	MissedOnWritten *time.Time `json:"missedOnWritten,omitempty"`
+
+	Note            *ProgressNote            `json:"note,omitempty"`
+	SubElementNotes []ProgressSubElementNote `json:"subElementNotes,omitempty"`
+}
+
+// ProgressVote is a single confidence vote. A nil confidence records that the vote was cleared.
+type ProgressVote struct {
+	Confidence *string   `json:"confidence"`
+	At         time.Time `json:"at"`
+}
+
+// ProgressReview is an element's spaced repetition schedule.
+type ProgressReview struct {
+	Repetitions  int        `json:"repetitions"`
+	IntervalDays int        `json:"intervalDays"`
+	EaseFactor   float64    `json:"easeFactor"`
+	RatedAt      *time.Time `json:"ratedAt"`
+	DueAt        time.Time  `json:"dueAt"`
+}
+
+type ProgressNote struct {
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ProgressSubElementNote struct {
+	SubElement string `json:"subElement"`
+	ProgressNote
+}
+
+// ProgressImport summarizes what was added by importing a progress file.
+type ProgressImport struct {
+	// Matched is the number of elements in the file that exist on this server.
+	Matched int
+
+	// Unknown lists the elements and ACS codes in the file that don't exist on this server.
+	Unknown []string
+
+	Votes   int64
+	Reviews int64
+	Missed  int64
+	Notes   int64
+}
+
+var progressConfidenceNames = map[ConfidenceLevel]string{
+	ConfidenceLevelLow:    "low",
+	ConfidenceLevelMedium: "medium",
+	ConfidenceLevelHigh:   "high",
+}
+
+func parseProgressConfidence(name string) (ConfidenceLevel, error) {
+	for level, n := range progressConfidenceNames {
+		if n == name {
+			return level, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown confidence level %q", name)
+}
+
+// parseSubElementID converts a sub-element letter into its position.
+func parseSubElementID(id string) (int32, error) {
+	if len(id) != 1 || id[0] < 'a' || id[0] > 'z' {
+		return 0, fmt.Errorf("invalid sub-element %q", id)
+	}
+
+	return int32(id[0] - 'a'), nil
+}
+
+// ExportProgress collects a user's studying list, confidence history, review schedules, missed
+// elements, and notes.
+func (m *ACSModel) ExportProgress(ctx context.Context, userID int32) (Progress, error) {
+	progress := Progress{
+		Version:    ProgressVersion,
+		ExportedAt: time.Now().UTC(),
+		Studying:   make([]string, 0),
+		Elements:   make([]ElementProgress, 0),
+	}
+
+	studying, err := m.q.ListStudyingACSForExport(ctx, userID)
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to list studied ACS for user %d: %v", userID, err)
+	}
+
+	progress.Studying = append(progress.Studying, studying...)
+
+	elements, err := m.q.ListElementsWithProgress(ctx, userID)
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to list elements with progress for user %d: %v", userID, err)
+	}
+
+	byID := make(map[int32]*ElementProgress, len(elements))
+	progress.Elements = make([]ElementProgress, len(elements))
+	for i, e := range elements {
+		progress.Elements[i] = ElementProgress{ID: e.FullPublicID}
+		byID[e.ID] = &progress.Elements[i]
+	}
+
+	events, err := m.q.ListConfidenceEventsForExport(ctx, userID)
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to list confidence events for user %d: %v", userID, err)
+	}
+
+	for _, ev := range events {
+		element := byID[ev.ElementID]
+
+		vote := ProgressVote{At: ev.CreatedAt.Time.UTC()}
+		element.Confidence = ""
+		if ev.Vote.Valid {
+			name := progressConfidenceNames[ConfidenceLevel(ev.Vote.Int16)]
+			vote.Confidence = &name
+			element.Confidence = name
+		}
+
+		element.History = append(element.History, vote)
+	}
+
+	reviews, err := m.q.ListElementReviewsForExport(ctx, userID)
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to list reviews for user %d: %v", userID, err)
+	}
+
+	for _, r := range reviews {
+		review := &ProgressReview{
+			Repetitions:  int(r.Repetitions),
+			IntervalDays: int(r.IntervalDays),
+			EaseFactor:   float64(r.EaseFactor),
+			DueAt:        r.DueAt.Time.UTC(),
+		}
+
+		if r.RatedAt.Valid {
+			ratedAt := r.RatedAt.Time.UTC()
+			review.RatedAt = &ratedAt
+		}
+
+		byID[r.ElementID].Review = review
+	}
+
+	missed, err := m.q.ListMissedElementsForExport(ctx, userID)
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to list missed elements for user %d: %v", userID, err)
+	}
+
+	for _, missedElement := range missed {
+		createdAt := missedElement.CreatedAt.Time.UTC()
+		byID[missedElement.ElementID].MissedOnWritten = &createdAt
+	}
+
+	notes, err := m.q.ListElementNotesForExport(ctx, userID)
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to list element notes for user %d: %v", userID, err)
+	}
+
+	for _, n := range notes {
+		byID[n.ElementID].Note = &ProgressNote{Content: n.Content, UpdatedAt: n.UpdatedAt.Time.UTC()}
+	}
+
+	subElementNotes, err := m.q.ListSubElementNotesForExport(ctx, userID)
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to list sub-element notes for user %d: %v", userID, err)
+	}
+
+	for _, n := range subElementNotes {
+		element := byID[n.ElementID]
+		element.SubElementNotes = append(element.SubElementNotes, ProgressSubElementNote{
+			SubElement:   SubElement{Order: n.SubElementOrder}.PublicID(),
+			ProgressNote: ProgressNote{Content: n.Content, UpdatedAt: n.UpdatedAt.Time.UTC()},
+		})
+	}
+
+	return progress, nil
+}
+
+// ValidateProgress checks a progress file before anything is imported so that a bad file doesn't
+// leave a partial import behind.
+func ValidateProgress(progress Progress) error {
+	if progress.Version < 1 || progress.Version > ProgressVersion {
+		return fmt.Errorf("unsupported progress file version %d", progress.Version)
+	}
+
+	for _, e := range progress.Elements {
+		if e.Confidence != "" {
+			if _, err := parseProgressConfidence(e.Confidence); err != nil {
+				return fmt.Errorf("%s: %v", e.ID, err)
+			}
+		}
+
+		for _, vote := range e.History {
+			if vote.Confidence != nil {
+				if _, err := parseProgressConfidence(*vote.Confidence); err != nil {
+					return fmt.Errorf("%s: %v", e.ID, err)
+				}
+			}
+		}
+
+		if e.Note != nil {
+			if err := validateProgressNote(*e.Note); err != nil {
+				return fmt.Errorf("%s: %v", e.ID, err)
+			}
+		}
+
+		for _, n := range e.SubElementNotes {
+			if _, err := parseSubElementID(n.SubElement); err != nil {
+				return fmt.Errorf("%s: %v", e.ID, err)
+			}
+
+			if err := validateProgressNote(n.ProgressNote); err != nil {
+				return fmt.Errorf("%s%s: %v", e.ID, n.SubElement, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateProgressNote(note ProgressNote) error {
+	content, err := validateNote(note.Content)
+	if err != nil {
+		return err
+	}
+
+	if content == "" {
+		return errors.New("notes can't be empty")
+	}
+
+	return nil
+}
+
+// ImportProgress merges a progress file into a user's existing progress. Votes that were already
+// recorded are skipped, and review schedules and notes are only replaced by newer copies, so
+// importing the same file twice has no further effect. Elements and ACS documents that don't
+// exist on this server are reported rather than causing the import to fail.
+func (m *ACSModel) ImportProgress(ctx context.Context, userID int32, progress Progress) (ProgressImport, error) {
+	if err := ValidateProgress(progress); err != nil {
+		return ProgressImport{}, err
+	}
+
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return ProgressImport{}, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback progress import transaction.", "error", err)
+		}
+	}()
+
+	q := queries.New(tx)
+
+	result := ProgressImport{Unknown: make([]string, 0)}
+
+	for _, acsID := range progress.Studying {
+		if _, err := q.GetACSDocument(ctx, acsID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				result.Unknown = append(result.Unknown, acsID)
+				continue
+			}
+
+			return ProgressImport{}, fmt.Errorf("failed to retrieve ACS %s: %v", acsID, err)
+		}
+
+		if err := setStudying(ctx, q, userID, acsID); err != nil {
+			return ProgressImport{}, err
+		}
+	}
+
+	codes := make([]string, len(progress.Elements))
+	for i, e := range progress.Elements {
+		codes[i] = e.ID
+	}
+
+	rows, err := q.ListElementsByFullPublicIDs(ctx, queries.ListElementsByFullPublicIDsParams{
+		FullPublicIds: codes,
+		UserID:        userID,
+	})
+	if err != nil {
+		return ProgressImport{}, fmt.Errorf("failed to resolve element IDs: %v", err)
+	}
+
+	elements := make(map[string]int32, len(rows))
+	for _, row := range rows {
+		elements[row.FullPublicID] = row.ID
+	}
+
+	exportedAt := progress.ExportedAt
+	if exportedAt.IsZero() {
+		exportedAt = time.Now()
+	}
+
+	for _, e := range progress.Elements {
+		elementID, ok := elements[e.ID]
+		if !ok {
+			result.Unknown = append(result.Unknown, e.ID)
+			continue
+		}
+
+		result.Matched++
+
+		if err := importElementProgress(ctx, q, userID, elementID, e, exportedAt, &result); err != nil {
+			return ProgressImport{}, fmt.Errorf("failed to import progress for %s: %v", e.ID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ProgressImport{}, fmt.Errorf("failed to commit progress import: %v", err)
+	}
+
+	m.logger.InfoContext(
+		ctx,
+		"Imported progress.",
+		"userID", userID,
+		"matched", result.Matched,
+		"unknown", len(result.Unknown),
+		"votes", result.Votes,
+		"reviews", result.Reviews,
+		"missed", result.Missed,
+		"notes", result.Notes,
+	)
+
+	return result, nil
+}
+
+// importElementProgress writes the progress for a single element, adding the number of rows
+// written to the import's totals.
+func importElementProgress(
+	ctx context.Context,
+	q *queries.Queries,
+	userID int32,
+	elementID int32,
+	e ElementProgress,
+	exportedAt time.Time,
+	result *ProgressImport,
+) error {
+	if len(e.History) == 0 && e.Confidence != "" {
+		// Files without history, e.g. ones written by hand, record the current vote as of the
+		// export. Unless the file includes a schedule, the element is reviewed as if it had just
+		// been rated.
+		level, _ := parseProgressConfidence(e.Confidence)
+		added, err := q.ImportConfidenceEvent(ctx, queries.ImportConfidenceEventParams{
+			UserID:    userID,
+			ElementID: elementID,
+			Vote:      pgtype.Int2{Int16: int16(level), Valid: true},
+			CreatedAt: pgtype.Timestamptz{Time: exportedAt, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import confidence vote: %v", err)
+		}
+
+		if added > 0 && e.Review == nil {
+			if _, err := scheduleReview(ctx, q, userID, elementID, level, exportedAt); err != nil {
+				return fmt.Errorf("failed to schedule review: %v", err)
+			}
+		}
+
+		result.Votes += added
+	}
+
+	for _, vote := range e.History {
+		params := queries.ImportConfidenceEventParams{
+			UserID:    userID,
+			ElementID: elementID,
+			CreatedAt: pgtype.Timestamptz{Time: vote.At, Valid: true},
+		}
+
+		if vote.Confidence != nil {
+			level, _ := parseProgressConfidence(*vote.Confidence)
+			params.Vote = pgtype.Int2{Int16: int16(level), Valid: true}
+		}
+
+		added, err := q.ImportConfidenceEvent(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to import confidence vote: %v", err)
+		}
+
+		result.Votes += added
+	}
+
+	if e.Review != nil {
+		params := queries.ImportElementReviewParams{
+			UserID:       userID,
+			ElementID:    elementID,
+			Repetitions:  int32(e.Review.Repetitions),
+			IntervalDays: int32(e.Review.IntervalDays),
+			EaseFactor:   float32(e.Review.EaseFactor),
+			DueAt:        pgtype.Timestamptz{Time: e.Review.DueAt, Valid: true},
+		}
+
+		if e.Review.RatedAt != nil {
+			params.RatedAt = pgtype.Timestamptz{Time: *e.Review.RatedAt, Valid: true}
+		}
+
+		added, err := q.ImportElementReview(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to import review schedule: %v", err)
+		}
+
+		result.Reviews += added
+	}
+
+	if e.MissedOnWritten != nil {
+		added, err := q.ImportMissedElement(ctx, queries.ImportMissedElementParams{
+			UserID:    userID,
+			ElementID: elementID,
+			CreatedAt: pgtype.Timestamptz{Time: *e.MissedOnWritten, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import missed element: %v", err)
+		}
+
+		result.Missed += added
+	}
+
+	if e.Note != nil {
+		added, err := q.ImportElementNote(ctx, queries.ImportElementNoteParams{
+			UserID:    userID,
+			ElementID: elementID,
+			Content:   strings.TrimSpace(e.Note.Content),
+			UpdatedAt: pgtype.Timestamptz{Time: e.Note.UpdatedAt, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import note: %v", err)
+		}
+
+		result.Notes += added
+	}
+
+	for _, n := range e.SubElementNotes {
+		order, _ := parseSubElementID(n.SubElement)
+		added, err := q.ImportSubElementNote(ctx, queries.ImportSubElementNoteParams{
+			UserID:          userID,
+			ElementID:       elementID,
+			SubElementOrder: order,
+			Content:         strings.TrimSpace(n.Content),
+			UpdatedAt:       pgtype.Timestamptz{Time: n.UpdatedAt, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import note for sub-element %s: %v", n.SubElement, err)
+		}
+
+		result.Notes += added
+	}
+
+	return nil
+}
@@ -1,8 +1,12 @@
 -- name: UpsertACS :one
-INSERT INTO acs (id, name)
-VALUES ($1, $2)
+-- The first edition loaded for a code becomes its current edition.
+INSERT INTO acs (id, code, edition, name, kind, is_current)
+VALUES (
+    $1, $2, $3, $4, $5,
+    NOT EXISTS (SELECT 1 FROM acs WHERE code = $2 AND is_current)
+)
 ON CONFLICT (id) DO UPDATE
-SET name = EXCLUDED.name
+SET name = EXCLUDED.name, kind = EXCLUDED.kind
 RETURNING *;
 
 -- name: UpsertArea :one
@@ -17,10 +21,10 @@ DELETE FROM acs_areas
 WHERE acs_id = $1 AND NOT (id = ANY(sqlc.arg(known_ids)::int[]));
 
 -- name: UpsertTask :one
-INSERT INTO acs_area_tasks (area_id, public_id, name, objective, note)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO acs_area_tasks (area_id, public_id, name, objective, note, applies_to)
+VALUES ($1, $2, $3, $4, $5, COALESCE(sqlc.narg(applies_to)::varchar(4)[], '{}'))
 ON CONFLICT (area_id, public_id) DO UPDATE
-SET name = EXCLUDED.name, objective = EXCLUDED.objective, note = EXCLUDED.note
+SET name = EXCLUDED.name, objective = EXCLUDED.objective, note = EXCLUDED.note, applies_to = EXCLUDED.applies_to
 RETURNING *;
 
 -- name: ClearUnknownTasks :execrows
@@ -39,10 +43,10 @@ DELETE FROM task_references
 WHERE task_id = $1 AND NOT (id = ANY(sqlc.arg(known_ids)::int[]));
 
 -- name: UpsertTaskElement :one
-INSERT INTO acs_elements (task_id, "type", public_id, content)
-VALUES ($1, $2, $3, $4)
+INSERT INTO acs_elements (task_id, "type", public_id, content, applies_to)
+VALUES ($1, $2, $3, $4, COALESCE(sqlc.narg(applies_to)::varchar(4)[], '{}'))
 ON CONFLICT (task_id, "type", public_id) DO UPDATE
-SET content = EXCLUDED.content
+SET content = EXCLUDED.content, applies_to = EXCLUDED.applies_to
 RETURNING *;
 
 -- name: ClearUnknownTaskElements :execrows
@@ -50,12 +54,150 @@ DELETE FROM acs_elements
 WHERE task_id = $1 AND NOT (id = ANY(sqlc.arg(known_ids)::int[]));
 
 -- name: UpsertSubElement :one
-INSERT INTO acs_subelements (element_id, "order", content)
-VALUES ($1, $2, $3)
+INSERT INTO acs_subelements (element_id, "order", content, applies_to)
+VALUES ($1, $2, $3, COALESCE(sqlc.narg(applies_to)::varchar(4)[], '{}'))
 ON CONFLICT (element_id, "order") DO UPDATE
-SET content = EXCLUDED.content
+SET content = EXCLUDED.content, applies_to = EXCLUDED.applies_to
 RETURNING *;
 
 -- name: ClearUnknownSubElements :execrows
 DELETE FROM acs_subelements
 WHERE element_id = $1 AND NOT (id = ANY(sqlc.arg(known_ids)::int[]));
+
+-- name: ListElementIDsByACS :many
+SELECT
+    e.id,
+    (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id
+FROM acs_elements e
+    JOIN acs_area_tasks t ON e.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+    JOIN acs ON a.acs_id = acs.id
+WHERE a.acs_id = $1;
+
+-- name: RemapConfidenceEvents :execrows
+UPDATE confidence_events
+SET element_id = (sqlc.arg(new_ids)::int[])[array_position(sqlc.arg(old_ids)::int[], element_id)]
+WHERE element_id = ANY(sqlc.arg(old_ids)::int[]);
+
+-- name: DeleteConfidenceEventsByElementIDs :exec
+DELETE FROM confidence_events
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: ListElementReviewsByElementIDs :many
+SELECT *
+FROM element_reviews
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: DeleteElementReviewsByElementIDs :exec
+DELETE FROM element_reviews
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: ListElementNotesForRemap :many
+SELECT *
+FROM element_notes
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: ListSubElementNotesForRemap :many
+SELECT *
+FROM sub_element_notes
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: DeleteElementNotesByElementIDs :exec
+DELETE FROM element_notes
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: DeleteSubElementNotesByElementIDs :exec
+DELETE FROM sub_element_notes
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: MergeElementNote :exec
+-- Notes moved onto an element that already has one are appended to it.
+INSERT INTO element_notes (user_id, element_id, content, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, element_id) DO UPDATE
+SET content = element_notes.content || E'\n\n' || EXCLUDED.content,
+    updated_at = GREATEST(element_notes.updated_at, EXCLUDED.updated_at);
+
+-- name: MergeSubElementNote :exec
+INSERT INTO sub_element_notes (user_id, element_id, sub_element_order, content, updated_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, element_id, sub_element_order) DO UPDATE
+SET content = sub_element_notes.content || E'\n\n' || EXCLUDED.content,
+    updated_at = GREATEST(sub_element_notes.updated_at, EXCLUDED.updated_at);
+
+-- name: ListElementSignOffsByElementIDs :many
+SELECT *
+FROM element_signoffs
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: DeleteElementSignOffsByElementIDs :exec
+DELETE FROM element_signoffs
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: MergeElementSignOff :exec
+-- A sign-off moved onto an element the student is already signed off on keeps
+-- the existing one.
+INSERT INTO element_signoffs (student_id, element_id, instructor_id, signed_off_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (student_id, element_id) DO NOTHING;
+
+-- name: ListMissedElementsByElementIDs :many
+SELECT *
+FROM missed_elements
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: DeleteMissedElementsByElementIDs :exec
+DELETE FROM missed_elements
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: MergeMissedElement :exec
+INSERT INTO missed_elements (user_id, element_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, element_id) DO UPDATE
+SET created_at = LEAST(missed_elements.created_at, EXCLUDED.created_at);
+
+-- name: ListExamElementGradesByElementIDs :many
+SELECT *
+FROM exam_element_grades
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: DeleteExamElementGradesByElementIDs :exec
+DELETE FROM exam_element_grades
+WHERE element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: MergeExamElementGrade :exec
+-- Grades moved onto an element that was already graded in the same exam keep
+-- the most recent grade.
+INSERT INTO exam_element_grades (exam_id, element_id, grade, graded_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (exam_id, element_id) DO UPDATE
+SET grade = EXCLUDED.grade, graded_at = EXCLUDED.graded_at
+WHERE EXCLUDED.graded_at > exam_element_grades.graded_at;
+
+-- name: ListElementUserData :many
+SELECT
+    e.id,
+    (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id,
+    (SELECT COUNT(*) FROM element_confidence c WHERE c.element_id = e.id)::int AS vote_count,
+    (SELECT COUNT(*) FROM confidence_events ev WHERE ev.element_id = e.id)::int AS event_count,
+    (
+        (SELECT COUNT(*) FROM element_notes n WHERE n.element_id = e.id)
+        + (SELECT COUNT(*) FROM sub_element_notes n WHERE n.element_id = e.id)
+    )::int AS note_count,
+    (SELECT COUNT(*) FROM element_signoffs s WHERE s.element_id = e.id)::int AS signoff_count,
+    (SELECT COUNT(*) FROM missed_elements m WHERE m.element_id = e.id)::int AS missed_count,
+    (SELECT COUNT(*) FROM exam_element_grades g WHERE g.element_id = e.id)::int AS grade_count
+FROM acs_elements e
+    JOIN acs_area_tasks t ON e.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+    JOIN acs ON a.acs_id = acs.id
+WHERE e.id = ANY(sqlc.arg(element_ids)::int[])
+    AND (
+        EXISTS (SELECT 1 FROM confidence_events ev WHERE ev.element_id = e.id)
+        OR EXISTS (SELECT 1 FROM element_notes n WHERE n.element_id = e.id)
+        OR EXISTS (SELECT 1 FROM sub_element_notes n WHERE n.element_id = e.id)
+        OR EXISTS (SELECT 1 FROM element_signoffs s WHERE s.element_id = e.id)
+        OR EXISTS (SELECT 1 FROM missed_elements m WHERE m.element_id = e.id)
+        OR EXISTS (SELECT 1 FROM exam_element_grades g WHERE g.element_id = e.id)
+    )
+ORDER BY a."order", t.public_id, e."type", e.public_id;
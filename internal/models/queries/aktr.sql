@@ -0,0 +1,24 @@
+-- name: ListElementsByFullPublicIDs :many
+-- Codes are shared by every edition of an ACS, so each code resolves to the edition the user is
+-- studying, or the current edition if they aren't studying any.
+SELECT DISTINCT ON (full_public_id)
+    e.id,
+    (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id
+FROM acs_elements e
+    JOIN acs_area_tasks t ON e.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+    JOIN acs ON a.acs_id = acs.id
+WHERE (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id) = ANY(sqlc.arg(full_public_ids)::text[])
+ORDER BY
+    full_public_id,
+    EXISTS (SELECT 1 FROM user_acs s WHERE s.acs_id = acs.id AND s.user_id = sqlc.arg(user_id)) DESC,
+    acs.is_current DESC;
+
+-- name: FlagMissedElement :exec
+INSERT INTO missed_elements (user_id, element_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: ClearMissedElement :exec
+DELETE FROM missed_elements
+WHERE user_id = $1 AND element_id = $2;
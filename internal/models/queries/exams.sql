@@ -0,0 +1,63 @@
+-- name: CreateExam :one
+INSERT INTO exams (acs_id, student_id, examiner_id, seed, scenario)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: AddExamTask :exec
+INSERT INTO exam_tasks (exam_id, task_id, position)
+VALUES ($1, $2, $3);
+
+-- name: GetExam :one
+-- Exams are visible to both the student and the examiner.
+SELECT
+    sqlc.embed(x),
+    acs.name AS acs_name,
+    s.username AS student_username,
+    e.username AS examiner_username
+FROM exams x
+    JOIN acs ON x.acs_id = acs.id
+    JOIN users s ON x.student_id = s.id
+    JOIN users e ON x.examiner_id = e.id
+WHERE x.id = sqlc.arg(exam_id)
+    AND (x.student_id = sqlc.arg(user_id) OR x.examiner_id = sqlc.arg(user_id));
+
+-- name: ListExamsByUser :many
+SELECT
+    sqlc.embed(x),
+    acs.name AS acs_name,
+    s.username AS student_username,
+    e.username AS examiner_username,
+    (SELECT COUNT(*) FROM exam_tasks t WHERE t.exam_id = x.id)::int AS task_count
+FROM exams x
+    JOIN acs ON x.acs_id = acs.id
+    JOIN users s ON x.student_id = s.id
+    JOIN users e ON x.examiner_id = e.id
+WHERE x.student_id = sqlc.arg(user_id) OR x.examiner_id = sqlc.arg(user_id)
+ORDER BY x.created_at DESC;
+
+-- name: ListExamTasks :many
+SELECT sqlc.embed(t), sqlc.embed(a)
+FROM exam_tasks et
+    JOIN acs_area_tasks t ON et.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+WHERE et.exam_id = $1
+ORDER BY et.position ASC;
+
+-- name: ListExamGrades :many
+SELECT element_id, grade
+FROM exam_element_grades
+WHERE exam_id = $1;
+
+-- name: GradeExamElement :execrows
+-- Only the examiner can grade elements, and only for elements of the tasks on
+-- the exam.
+INSERT INTO exam_element_grades (exam_id, element_id, grade)
+SELECT x.id, e.id, sqlc.arg(grade)::exam_grade
+FROM exams x
+    JOIN exam_tasks et ON et.exam_id = x.id
+    JOIN acs_elements e ON e.task_id = et.task_id
+WHERE x.id = sqlc.arg(exam_id)
+    AND x.examiner_id = sqlc.arg(examiner_id)
+    AND e.id = sqlc.arg(element_id)
+ON CONFLICT (exam_id, element_id) DO UPDATE
+SET grade = EXCLUDED.grade, graded_at = now();
@@ -0,0 +1,42 @@
+-- name: GetACSDocument :one
+SELECT *
+FROM acs
+WHERE id = $1;
+
+-- name: ListAreasForExport :many
+SELECT *
+FROM acs_areas
+WHERE acs_id = $1
+ORDER BY "order" ASC;
+
+-- name: ListTasksForExport :many
+SELECT t.*
+FROM acs_area_tasks t
+    JOIN acs_areas a ON t.area_id = a.id
+WHERE a.acs_id = $1
+ORDER BY a."order" ASC, t.public_id ASC;
+
+-- name: ListTaskReferencesForExport :many
+SELECT r.*
+FROM task_references r
+    JOIN acs_area_tasks t ON r.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+WHERE a.acs_id = $1
+ORDER BY r.task_id ASC, r."order" ASC;
+
+-- name: ListElementsForExport :many
+SELECT e.*
+FROM acs_elements e
+    JOIN acs_area_tasks t ON e.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+WHERE a.acs_id = $1
+ORDER BY e.task_id ASC, e."type" ASC, e.public_id ASC;
+
+-- name: ListSubElementsForExport :many
+SELECT s.*
+FROM acs_subelements s
+    JOIN acs_elements e ON s.element_id = e.id
+    JOIN acs_area_tasks t ON e.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+WHERE a.acs_id = $1
+ORDER BY s.element_id ASC, s."order" ASC;
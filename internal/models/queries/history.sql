@@ -0,0 +1,60 @@
+-- name: ListAreaConfidenceHistory :many
+WITH weeks AS (
+    SELECT generate_series(
+        date_trunc('week', now()) - (sqlc.arg(weeks)::int - 1) * interval '1 week',
+        date_trunc('week', now()),
+        interval '1 week'
+    ) AS week_start
+), elements AS (
+    SELECT e.id
+    FROM acs_elements e
+        JOIN acs_area_tasks t ON e.task_id = t.id
+    WHERE t.area_id = sqlc.arg(area_id)
+        AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
+        AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+)
+SELECT
+    w.week_start::timestamptz AS week_start,
+    COALESCE((
+        SELECT SUM(latest.vote)
+        FROM (
+            SELECT DISTINCT ON (ev.element_id) ev.vote
+            FROM confidence_events ev
+            WHERE ev.user_id = sqlc.arg(user_id)
+                AND ev.element_id = ANY(SELECT id FROM elements)
+                AND ev.created_at < w.week_start + interval '1 week'
+            ORDER BY ev.element_id, ev.created_at DESC, ev.id DESC
+        ) latest
+    ), 0)::int AS votes,
+    (SELECT COUNT(*) * 3 FROM elements)::int AS possible
+FROM weeks w
+ORDER BY w.week_start ASC;
+
+-- name: ListTaskConfidenceHistory :many
+WITH weeks AS (
+    SELECT generate_series(
+        date_trunc('week', now()) - (sqlc.arg(weeks)::int - 1) * interval '1 week',
+        date_trunc('week', now()),
+        interval '1 week'
+    ) AS week_start
+), elements AS (
+    SELECT e.id
+    FROM acs_elements e
+    WHERE e.task_id = sqlc.arg(task_id) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+)
+SELECT
+    w.week_start::timestamptz AS week_start,
+    COALESCE((
+        SELECT SUM(latest.vote)
+        FROM (
+            SELECT DISTINCT ON (ev.element_id) ev.vote
+            FROM confidence_events ev
+            WHERE ev.user_id = sqlc.arg(user_id)
+                AND ev.element_id = ANY(SELECT id FROM elements)
+                AND ev.created_at < w.week_start + interval '1 week'
+            ORDER BY ev.element_id, ev.created_at DESC, ev.id DESC
+        ) latest
+    ), 0)::int AS votes,
+    (SELECT COUNT(*) * 3 FROM elements)::int AS possible
+FROM weeks w
+ORDER BY w.week_start ASC;
@@ -0,0 +1,32 @@
+-- name: UpsertElementNote :exec
+INSERT INTO element_notes (user_id, element_id, content)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, element_id) DO UPDATE
+SET content = EXCLUDED.content, updated_at = now();
+
+-- name: DeleteElementNote :exec
+DELETE FROM element_notes
+WHERE user_id = $1 AND element_id = $2;
+
+-- name: UpsertSubElementNote :execrows
+-- Nothing is written if the element has no sub-element at the given position.
+INSERT INTO sub_element_notes (user_id, element_id, sub_element_order, content)
+SELECT sqlc.arg(user_id), s.element_id, s."order", sqlc.arg(content)
+FROM acs_subelements s
+WHERE s.element_id = sqlc.arg(element_id) AND s."order" = sqlc.arg(sub_element_order)
+ON CONFLICT (user_id, element_id, sub_element_order) DO UPDATE
+SET content = EXCLUDED.content, updated_at = now();
+
+-- name: DeleteSubElementNote :exec
+DELETE FROM sub_element_notes
+WHERE user_id = $1 AND element_id = $2 AND sub_element_order = $3;
+
+-- name: ListElementNotesByElementIDs :many
+SELECT *
+FROM element_notes
+WHERE user_id = $1 AND element_id = ANY(sqlc.arg(element_ids)::int[]);
+
+-- name: ListSubElementNotesByElementIDs :many
+SELECT *
+FROM sub_element_notes
+WHERE user_id = $1 AND element_id = ANY(sqlc.arg(element_ids)::int[]);
@@ -0,0 +1,103 @@
+-- name: ListStudyingACSForExport :many
+SELECT acs_id
+FROM user_acs
+WHERE user_id = $1
+ORDER BY acs_id ASC;
+
+-- name: ListElementsWithProgress :many
+-- Every element the user has any progress on, in document order.
+SELECT
+    e.id,
+    (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id
+FROM acs_elements e
+    JOIN acs_area_tasks t ON e.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+    JOIN acs ON a.acs_id = acs.id
+WHERE e.id IN (
+    SELECT ev.element_id FROM confidence_events ev WHERE ev.user_id = sqlc.arg(user_id)
+    UNION
+    SELECT r.element_id FROM element_reviews r WHERE r.user_id = sqlc.arg(user_id)
+    UNION
+    SELECT m.element_id FROM missed_elements m WHERE m.user_id = sqlc.arg(user_id)
+    UNION
+    SELECT n.element_id FROM element_notes n WHERE n.user_id = sqlc.arg(user_id)
+    UNION
+    SELECT sn.element_id FROM sub_element_notes sn WHERE sn.user_id = sqlc.arg(user_id)
+)
+ORDER BY a.acs_id, a."order", t.public_id, e."type", e.public_id;
+
+-- name: ListConfidenceEventsForExport :many
+SELECT *
+FROM confidence_events
+WHERE user_id = $1
+ORDER BY element_id, created_at, id;
+
+-- name: ListElementReviewsForExport :many
+SELECT *
+FROM element_reviews
+WHERE user_id = $1;
+
+-- name: ListMissedElementsForExport :many
+SELECT *
+FROM missed_elements
+WHERE user_id = $1;
+
+-- name: ListElementNotesForExport :many
+SELECT *
+FROM element_notes
+WHERE user_id = $1;
+
+-- name: ListSubElementNotesForExport :many
+SELECT *
+FROM sub_element_notes
+WHERE user_id = $1
+ORDER BY element_id, sub_element_order;
+
+-- name: ImportConfidenceEvent :execrows
+-- Events that were already imported are skipped so a file can be imported more
+-- than once.
+INSERT INTO confidence_events (user_id, element_id, vote, created_at)
+SELECT sqlc.arg(user_id), sqlc.arg(element_id), sqlc.narg(vote)::smallint, sqlc.arg(created_at)
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM confidence_events ev
+    WHERE ev.user_id = sqlc.arg(user_id)
+        AND ev.element_id = sqlc.arg(element_id)
+        AND ev.created_at = sqlc.arg(created_at)
+        AND ev.vote IS NOT DISTINCT FROM sqlc.narg(vote)::smallint
+);
+
+-- name: ImportElementReview :execrows
+-- An existing schedule is only replaced by one that was rated more recently.
+INSERT INTO element_reviews (user_id, element_id, repetitions, interval_days, ease_factor, rated_at, due_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, element_id) DO UPDATE
+SET repetitions = EXCLUDED.repetitions,
+    interval_days = EXCLUDED.interval_days,
+    ease_factor = EXCLUDED.ease_factor,
+    rated_at = EXCLUDED.rated_at,
+    due_at = EXCLUDED.due_at
+WHERE element_reviews.rated_at IS NULL OR EXCLUDED.rated_at > element_reviews.rated_at;
+
+-- name: ImportMissedElement :execrows
+INSERT INTO missed_elements (user_id, element_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: ImportElementNote :execrows
+-- An existing note is only replaced by one that was updated more recently.
+INSERT INTO element_notes (user_id, element_id, content, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, element_id) DO UPDATE
+SET content = EXCLUDED.content, updated_at = EXCLUDED.updated_at
+WHERE EXCLUDED.updated_at > element_notes.updated_at;
+
+-- name: ImportSubElementNote :execrows
+-- Nothing is written if the element has no sub-element at the given position.
+INSERT INTO sub_element_notes (user_id, element_id, sub_element_order, content, updated_at)
+SELECT sqlc.arg(user_id), s.element_id, s."order", sqlc.arg(content), sqlc.arg(updated_at)
+FROM acs_subelements s
+WHERE s.element_id = sqlc.arg(element_id) AND s."order" = sqlc.arg(sub_element_order)
+ON CONFLICT (user_id, element_id, sub_element_order) DO UPDATE
+SET content = EXCLUDED.content, updated_at = EXCLUDED.updated_at
+WHERE EXCLUDED.updated_at > sub_element_notes.updated_at;
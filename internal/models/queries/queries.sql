@@ -1,3 +1,108 @@
+-- name: ListACS :many
+WITH area_count AS (
+    SELECT acs_id, COUNT(id) AS areas
+    FROM acs_areas
+    GROUP BY acs_id
+), votes AS (
+    SELECT a.acs_id AS acs_id, SUM(c.vote) AS votes
+    FROM element_confidence c
+        JOIN acs_elements e ON c.element_id = e.id
+        JOIN acs_area_tasks t ON e.task_id = t.id
+        JOIN acs_areas a ON t.area_id = a.id
+    WHERE c.user_id = sqlc.arg(user_id)
+        AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
+        AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+    GROUP BY a.acs_id
+), max_votes AS (
+    SELECT a.acs_id AS acs_id, COUNT(e.id) * 3 AS max_votes
+    FROM acs_elements e
+        JOIN acs_area_tasks t ON e.task_id = t.id
+        JOIN acs_areas a ON t.area_id = a.id
+    WHERE task_applies_to_user(t.applies_to, sqlc.arg(user_id)) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+    GROUP BY a.acs_id
+)
+SELECT
+    sqlc.embed(acs),
+    COALESCE((SELECT areas FROM area_count WHERE acs_id = acs.id), 0)::int AS area_count,
+    COALESCE((SELECT votes FROM votes WHERE acs_id = acs.id), 0)::int AS votes,
+    COALESCE((SELECT max_votes FROM max_votes WHERE acs_id = acs.id), 0)::int AS max_votes,
+    EXISTS (
+        SELECT 1 FROM user_acs s WHERE s.acs_id = acs.id AND s.user_id = sqlc.arg(user_id)
+    )::bool AS studying
+FROM acs
+ORDER BY acs.code ASC, acs.is_current DESC, acs.edition DESC;
+
+-- name: GetACSByID :one
+WITH area_count AS (
+    SELECT COUNT(id) AS areas
+    FROM acs_areas
+    WHERE acs_id = sqlc.arg(acs_id)
+), votes AS (
+    SELECT SUM(c.vote) AS votes
+    FROM element_confidence c
+        JOIN acs_elements e ON c.element_id = e.id
+        JOIN acs_area_tasks t ON e.task_id = t.id
+        JOIN acs_areas a ON t.area_id = a.id
+    WHERE a.acs_id = sqlc.arg(acs_id)
+        AND c.user_id = sqlc.arg(user_id)
+        AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
+        AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+), max_votes AS (
+    SELECT COUNT(e.id) * 3 AS max_votes
+    FROM acs_elements e
+        JOIN acs_area_tasks t ON e.task_id = t.id
+        JOIN acs_areas a ON t.area_id = a.id
+    WHERE a.acs_id = sqlc.arg(acs_id)
+        AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
+        AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+)
+SELECT
+    sqlc.embed(acs),
+    COALESCE((SELECT areas FROM area_count), 0)::int AS area_count,
+    COALESCE((SELECT votes FROM votes), 0)::int AS votes,
+    COALESCE((SELECT max_votes FROM max_votes), 0)::int AS max_votes,
+    EXISTS (
+        SELECT 1 FROM user_acs s WHERE s.acs_id = acs.id AND s.user_id = sqlc.arg(user_id)
+    )::bool AS studying
+FROM acs
+WHERE acs.id = sqlc.arg(acs_id);
+
+-- name: AddUserACS :exec
+INSERT INTO user_acs (user_id, acs_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveUserACS :exec
+DELETE FROM user_acs
+WHERE user_id = $1 AND acs_id = $2;
+
+-- name: RemoveUserOtherEditions :exec
+-- Users study a single edition of each ACS.
+DELETE FROM user_acs s
+USING acs studied, acs chosen
+WHERE s.user_id = sqlc.arg(user_id)
+    AND s.acs_id = studied.id
+    AND chosen.id = sqlc.arg(acs_id)
+    AND studied.code = chosen.code
+    AND studied.id <> chosen.id;
+
+-- name: ListEditions :many
+SELECT editions.*
+FROM acs editions
+    JOIN acs ON editions.code = acs.code
+WHERE acs.id = $1
+ORDER BY editions.is_current DESC, editions.edition DESC;
+
+-- name: ClearCurrentEdition :exec
+UPDATE acs
+SET is_current = false
+WHERE code = (SELECT code FROM acs edition WHERE edition.id = $1);
+
+-- name: SetCurrentEdition :execrows
+UPDATE acs
+SET is_current = true
+WHERE id = $1;
+
 -- name: GetAreaByPublicID :one
 SELECT *
 FROM acs_areas
@@ -6,11 +111,12 @@ WHERE acs_id = $1 AND public_id = $2;
 -- name: ListAreasByACS :many
 WITH areas AS (
     SELECT * FROM acs_areas
-    WHERE acs_areas.acs_id = $1
+    WHERE acs_areas.acs_id = sqlc.arg(acs_id)
 ), task_count AS (
     SELECT a.id AS area_id, COUNT(t.id) as tasks
     FROM acs_area_tasks t
         LEFT JOIN areas a ON t.area_id = a.id
+    WHERE task_applies_to_user(t.applies_to, sqlc.arg(user_id))
     GROUP BY a.id
 ), votes AS (
     SELECT t.area_id AS area_id, SUM(c.vote) AS votes
@@ -18,19 +124,36 @@ WITH areas AS (
         LEFT JOIN acs_elements e ON c.element_id = e.id
         LEFT JOIN acs_area_tasks t ON e.task_id = t.id
     WHERE t.area_id = ANY(SELECT id FROM areas)
+        AND c.user_id = sqlc.arg(user_id)
+        AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
+        AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
     GROUP BY t.area_id
 ), max_votes AS (
-    SELECT t.area_id AS area_id, COUNT(e.id) * 3 AS max_votes
+    SELECT t.area_id AS area_id, COUNT(e.id) AS elements, COUNT(e.id) * 3 AS max_votes
     FROM acs_elements e
         LEFT JOIN acs_area_tasks t ON e.task_id = t.id
     WHERE t.area_id = ANY(SELECT id FROM areas)
+        AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
+        AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+    GROUP BY t.area_id
+), signoffs AS (
+    SELECT t.area_id AS area_id, COUNT(s.element_id) AS signed_off
+    FROM element_signoffs s
+        JOIN acs_elements e ON s.element_id = e.id
+        JOIN acs_area_tasks t ON e.task_id = t.id
+    WHERE t.area_id = ANY(SELECT id FROM areas)
+        AND s.student_id = sqlc.arg(user_id)
+        AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
+        AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
     GROUP BY t.area_id
 )
 SELECT
     sqlc.embed(a),
     COALESCE((SELECT tasks FROM task_count WHERE area_id = a.id), 0)::int AS task_count,
     COALESCE((SELECT votes FROM votes WHERE area_id = a.id), 0)::int AS votes,
-    COALESCE((SELECT max_votes FROM max_votes WHERE area_id = a.id), 0)::int as max_votes
+    COALESCE((SELECT max_votes FROM max_votes WHERE area_id = a.id), 0)::int as max_votes,
+    COALESCE((SELECT elements FROM max_votes WHERE area_id = a.id), 0)::int AS element_count,
+    COALESCE((SELECT signed_off FROM signoffs WHERE area_id = a.id), 0)::int AS signed_off_count
 FROM acs_areas a
 WHERE a.id = ANY(SELECT id FROM areas)
 ORDER BY a."order" ASC;
@@ -40,36 +163,67 @@ WITH task_element_counts AS (
     SELECT e.task_id AS task_id, e.type AS "type", COUNT(e.id) AS "count"
     FROM acs_elements e
         LEFT JOIN acs_area_tasks t ON e.task_id = t.id
-    WHERE t.area_id = $1
+    WHERE t.area_id = sqlc.arg(area_id) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
     GROUP BY e.task_id, e.type
 ), max_votes AS (
     SELECT COALESCE(COUNT(e.id) * 3, 0) AS max_votes, e.task_id AS task_id
     FROM acs_elements e
         LEFT JOIN acs_area_tasks t ON e.task_id = t.id
-    WHERE t.area_id = $1
+    WHERE t.area_id = sqlc.arg(area_id) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
     GROUP BY e.task_id
 ), votes AS (
     SELECT e.task_id AS task_id, COALESCE(SUM(c.vote), 0) AS votes
     FROM acs_elements e
-        LEFT JOIN element_confidence c ON e.id = c.element_id
+        LEFT JOIN element_confidence c ON e.id = c.element_id AND c.user_id = sqlc.arg(user_id)
         LEFT JOIN acs_area_tasks t ON e.task_id = t.id
-    WHERE t.area_id = $1
+    WHERE t.area_id = sqlc.arg(area_id) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+    GROUP BY e.task_id
+), signoffs AS (
+    SELECT e.task_id AS task_id, COUNT(s.element_id) AS signed_off
+    FROM element_signoffs s
+        JOIN acs_elements e ON s.element_id = e.id
+        JOIN acs_area_tasks t ON e.task_id = t.id
+    WHERE t.area_id = sqlc.arg(area_id) AND s.student_id = sqlc.arg(user_id) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
     GROUP BY e.task_id
 )
 SELECT
     sqlc.embed(t),
     sqlc.embed(a),
-    (a.acs_id || '.' || a.public_id || '.' || t.public_id)::text AS full_public_id,
+    (acs.code || '.' || a.public_id || '.' || t.public_id)::text AS full_public_id,
     COALESCE((SELECT votes FROM votes WHERE task_id = t.id), 0)::int AS votes,
     COALESCE((SELECT max_votes FROM max_votes WHERE task_id = t.id), 0)::int AS max_votes,
+    COALESCE((SELECT signed_off FROM signoffs WHERE task_id = t.id), 0)::int AS signed_off_count,
     COALESCE((SELECT "count" FROM task_element_counts WHERE task_id = t.id AND "type" = 'K'), 0)::int AS knowledge_element_count,
     COALESCE((SELECT "count" FROM task_element_counts WHERE task_id = t.id AND "type" = 'R'), 0)::int AS risk_element_count,
-    COALESCE((SELECT "count" FROM task_element_counts WHERE task_id = t.id AND "type" = 'S'), 0)::int AS skill_element_count
+    COALESCE((SELECT "count" FROM task_element_counts WHERE task_id = t.id AND "type" = 'S'), 0)::int AS skill_element_count,
+    COALESCE((SELECT "count" FROM task_element_counts WHERE task_id = t.id AND "type" = 'E'), 0)::int AS pts_element_count
 FROM acs_area_tasks t
     LEFT JOIN acs_areas a ON t.area_id = a.id
-WHERE t.area_id = $1
+    LEFT JOIN acs ON a.acs_id = acs.id
+WHERE t.area_id = sqlc.arg(area_id) AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
 ORDER BY t.public_id ASC;
 
+-- name: ListWeakestTasks :many
+-- Tasks are ranked by the share of their possible votes the user has given, so tasks without any
+-- votes come first. Tasks that don't apply to the user's class rating are left out.
+SELECT
+    sqlc.embed(t),
+    sqlc.embed(a),
+    (acs.code || '.' || a.public_id || '.' || t.public_id)::text AS full_public_id,
+    COALESCE(SUM(c.vote), 0)::int AS votes,
+    (COUNT(e.id) * 3)::int AS max_votes
+FROM acs_area_tasks t
+    JOIN acs_areas a ON t.area_id = a.id
+    JOIN acs ON a.acs_id = acs.id
+    JOIN acs_elements e ON e.task_id = t.id
+    LEFT JOIN element_confidence c ON e.id = c.element_id AND c.user_id = sqlc.arg(user_id)
+WHERE a.acs_id = sqlc.arg(acs_id)
+    AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
+    AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+GROUP BY t.id, a.id, acs.code
+ORDER BY COALESCE(SUM(c.vote), 0)::float / (COUNT(e.id) * 3) ASC, a."order" ASC, t.public_id ASC
+LIMIT sqlc.arg(count);
+
 -- name: GetTaskByPublicID :one
 WITH tasks AS (
     SELECT t.id AS id
@@ -77,15 +231,15 @@ WITH tasks AS (
         LEFT JOIN acs_areas a ON t.area_id = a.id
     WHERE a.acs_id = sqlc.arg(acs)::text AND a.public_id = sqlc.arg(area_id)::text AND t.public_id = sqlc.arg(task_id)::text
 ), max_votes AS (
-    SELECT COALESCE(COUNT(id) * 3, 0) AS max_votes, task_id
-    FROM acs_elements
-    WHERE task_id = ANY(SELECT id FROM tasks)
-    GROUP BY task_id
+    SELECT COALESCE(COUNT(e.id) * 3, 0) AS max_votes, e.task_id
+    FROM acs_elements e
+    WHERE e.task_id = ANY(SELECT id FROM tasks) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+    GROUP BY e.task_id
 ), votes AS (
     SELECT e.task_id AS task_id, COALESCE(SUM(c.vote), 0) AS votes
     FROM acs_elements e
-        LEFT JOIN element_confidence c ON e.id = c.element_id
-    WHERE e.task_id = ANY(SELECT id FROM tasks)
+        LEFT JOIN element_confidence c ON e.id = c.element_id AND c.user_id = sqlc.arg(user_id)
+    WHERE e.task_id = ANY(SELECT id FROM tasks) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
     GROUP BY e.task_id
 )
 SELECT
@@ -102,17 +256,17 @@ WITH tasks AS (
     SELECT t.id AS id
     FROM acs_area_tasks t
         JOIN acs_elements e ON t.id = e.task_id
-    WHERE e.id = $1
+    WHERE e.id = sqlc.arg(element_id)
 ), max_votes AS (
-    SELECT COALESCE(COUNT(id) * 3, 0) AS max_votes, task_id
-    FROM acs_elements
-    WHERE task_id = ANY(SELECT id FROM tasks)
-    GROUP BY task_id
+    SELECT COALESCE(COUNT(e.id) * 3, 0) AS max_votes, e.task_id
+    FROM acs_elements e
+    WHERE e.task_id = ANY(SELECT id FROM tasks) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+    GROUP BY e.task_id
 ), votes AS (
     SELECT e.task_id AS task_id, COALESCE(SUM(c.vote), 0) AS votes
     FROM acs_elements e
-        LEFT JOIN element_confidence c ON e.id = c.element_id
-    WHERE e.task_id = ANY(SELECT id FROM tasks)
+        LEFT JOIN element_confidence c ON e.id = c.element_id AND c.user_id = sqlc.arg(user_id)
+    WHERE e.task_id = ANY(SELECT id FROM tasks) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
     GROUP BY e.task_id
 )
 SELECT
@@ -126,7 +280,9 @@ WHERE t.id = ANY(SELECT id FROM tasks);
 
 -- name: GetTaskConfidenceByTaskID :one
 WITH task_elements AS (
-    SELECT id FROM acs_elements WHERE task_id = $1
+    SELECT e.id
+    FROM acs_elements e
+    WHERE e.task_id = sqlc.arg(task_id) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
 ), max_votes AS (
     SELECT COALESCE(COUNT(*) * 3, 0) AS max_votes FROM task_elements
 )
@@ -134,7 +290,7 @@ SELECT
     COALESCE(SUM(c.vote), 0)::int AS votes,
     (SELECT max_votes FROM max_votes)::int AS possible
 FROM element_confidence c
-WHERE c.element_id IN (SELECT id FROM task_elements);
+WHERE c.element_id IN (SELECT id FROM task_elements) AND c.user_id = sqlc.arg(user_id);
 
 -- name: GetTaskReferencesByTaskID :many
 SELECT *
@@ -144,36 +300,80 @@ ORDER BY "order" ASC;
 
 -- name: GetElementPublicIDByID :one
 SELECT
-    (a.acs_id || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id
+    (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id
 FROM acs_elements e
     LEFT JOIN acs_area_tasks t ON e.task_id = t.id
     LEFT JOIN acs_areas a on t.area_id = a.id
+    LEFT JOIN acs ON a.acs_id = acs.id
 WHERE e.id = $1;
 
 -- name: ListElementsByTaskID :many
 SELECT
     sqlc.embed(e),
     c.vote AS confidence_vote,
-    (a.acs_id || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id
+    r.due_at AS review_due_at,
+    EXISTS (
+        SELECT 1 FROM missed_elements m WHERE m.element_id = e.id AND m.user_id = sqlc.arg(user_id)
+    )::bool AS missed_on_written,
+    (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id,
+    s.signed_off_at,
+    i.username AS signed_off_by
 FROM acs_elements e
     LEFT JOIN acs_area_tasks t ON e.task_id = t.id
     LEFT JOIN acs_areas a ON t.area_id = a.id
-    LEFT JOIN element_confidence c ON e.id = c.element_id
-WHERE e.task_id = $1
+    LEFT JOIN acs ON a.acs_id = acs.id
+    LEFT JOIN element_confidence c ON e.id = c.element_id AND c.user_id = sqlc.arg(user_id)
+    LEFT JOIN element_reviews r ON e.id = r.element_id AND r.user_id = sqlc.arg(user_id)
+    LEFT JOIN element_signoffs s ON e.id = s.element_id AND s.student_id = sqlc.arg(user_id)
+    LEFT JOIN users i ON s.instructor_id = i.id
+WHERE e.task_id = sqlc.arg(task_id) AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
 ORDER BY e."type", e.public_id ASC;
 
+-- name: GetElementByID :one
+SELECT
+    sqlc.embed(e),
+    c.vote AS confidence_vote,
+    r.due_at AS review_due_at,
+    EXISTS (
+        SELECT 1 FROM missed_elements m WHERE m.element_id = e.id AND m.user_id = sqlc.arg(user_id)
+    )::bool AS missed_on_written,
+    (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id,
+    s.signed_off_at,
+    i.username AS signed_off_by
+FROM acs_elements e
+    LEFT JOIN acs_area_tasks t ON e.task_id = t.id
+    LEFT JOIN acs_areas a ON t.area_id = a.id
+    LEFT JOIN acs ON a.acs_id = acs.id
+    LEFT JOIN element_confidence c ON e.id = c.element_id AND c.user_id = sqlc.arg(user_id)
+    LEFT JOIN element_reviews r ON e.id = r.element_id AND r.user_id = sqlc.arg(user_id)
+    LEFT JOIN element_signoffs s ON e.id = s.element_id AND s.student_id = sqlc.arg(user_id)
+    LEFT JOIN users i ON s.instructor_id = i.id
+WHERE e.id = sqlc.arg(element_id);
+
 -- name: SetElementConfidence :exec
-INSERT INTO element_confidence (element_id, vote)
-VALUES ($1, $2)
-ON CONFLICT (element_id) DO UPDATE
-SET vote = EXCLUDED.vote;
+INSERT INTO confidence_events (user_id, element_id, vote)
+VALUES (sqlc.arg(user_id), sqlc.arg(element_id), sqlc.arg(vote)::smallint);
 
 -- name: ClearElementConfidence :exec
-DELETE FROM element_confidence
-WHERE element_id = $1;
+INSERT INTO confidence_events (user_id, element_id, vote)
+SELECT c.user_id, c.element_id, NULL
+FROM element_confidence c
+WHERE c.user_id = $1 AND c.element_id = $2;
 
 -- name: ListSubElementsByElementIDs :many
+-- Sub-elements keep their order when others are left out for the user's class
+-- rating, so they are labeled the same way as in the ACS.
 SELECT *
 FROM acs_subelements
-WHERE element_id = ANY ($1::int[])
+WHERE element_id = ANY (sqlc.arg(element_ids)::int[]) AND task_applies_to_user(applies_to, sqlc.arg(user_id))
 ORDER BY "order" ASC;
+
+-- name: SignOffElement :exec
+INSERT INTO element_signoffs (student_id, element_id, instructor_id)
+VALUES (sqlc.arg(student_id), sqlc.arg(element_id), sqlc.arg(instructor_id))
+ON CONFLICT (student_id, element_id) DO UPDATE
+SET instructor_id = EXCLUDED.instructor_id, signed_off_at = now();
+
+-- name: RemoveElementSignOff :exec
+DELETE FROM element_signoffs
+WHERE student_id = $1 AND element_id = $2;
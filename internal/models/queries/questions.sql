@@ -0,0 +1,154 @@
+-- name: UpsertQuestion :one
+INSERT INTO questions (public_id, prompt, explanation, answer)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (public_id) DO UPDATE
+SET prompt = EXCLUDED.prompt, explanation = EXCLUDED.explanation, answer = EXCLUDED.answer
+RETURNING id;
+
+-- name: UpsertQuestionChoice :exec
+INSERT INTO question_choices (question_id, "order", content)
+VALUES ($1, $2, $3)
+ON CONFLICT (question_id, "order") DO UPDATE
+SET content = EXCLUDED.content;
+
+-- name: ClearExtraQuestionChoices :exec
+DELETE FROM question_choices
+WHERE question_id = $1 AND "order" >= sqlc.arg(choice_count)::int;
+
+-- name: ClearQuestionElements :exec
+DELETE FROM question_elements
+WHERE question_id = $1;
+
+-- name: AddQuestionElements :exec
+INSERT INTO question_elements (question_id, element_code)
+SELECT sqlc.arg(question_id), unnest(sqlc.arg(element_codes)::text[]);
+
+-- name: ListKnownElementCodes :many
+-- Codes are known if they match an element in any edition of an ACS.
+SELECT DISTINCT (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS code
+FROM acs_elements e
+    JOIN acs_area_tasks t ON e.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+    JOIN acs ON a.acs_id = acs.id
+WHERE (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id) = ANY(sqlc.arg(codes)::text[]);
+
+-- name: CreatePracticeTest :one
+INSERT INTO practice_tests (user_id, acs_id, area_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: PickPracticeTestQuestions :execrows
+-- Questions are drawn from the ones tagged with an element in the test's ACS or
+-- area, favoring questions the user hasn't answered or got wrong the last time
+-- they saw them. The weighted order uses the same method as study sessions.
+INSERT INTO practice_test_questions (test_id, question_id, position)
+SELECT
+    sqlc.arg(test_id)::int,
+    picked.id,
+    row_number() OVER (ORDER BY picked.sort_key)
+FROM (
+    SELECT
+        q.id,
+        -ln(1.0 - random()) / (
+            CASE (
+                SELECT ptq.correct
+                FROM practice_test_questions ptq
+                    JOIN practice_tests pt ON ptq.test_id = pt.id
+                WHERE ptq.question_id = q.id
+                    AND pt.user_id = sqlc.arg(user_id)
+                    AND ptq.answered_at IS NOT NULL
+                ORDER BY ptq.answered_at DESC
+                LIMIT 1
+            )
+                WHEN false THEN 6
+                WHEN true THEN 1
+                ELSE 3
+            END
+        ) AS sort_key
+    FROM questions q
+    WHERE EXISTS (
+        SELECT 1
+        FROM acs_elements e
+            JOIN acs_area_tasks t ON e.task_id = t.id
+            JOIN acs_areas a ON t.area_id = a.id
+            JOIN acs ON a.acs_id = acs.id
+            JOIN question_elements qe
+                ON qe.element_code = (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)
+        WHERE qe.question_id = q.id
+            AND a.acs_id = sqlc.arg(acs_id)
+            AND (sqlc.narg(area_id)::int IS NULL OR a.id = sqlc.narg(area_id)::int)
+            AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
+            AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+    )
+    ORDER BY sort_key
+    LIMIT sqlc.arg(question_count)::int
+) picked;
+
+-- name: GetPracticeTest :one
+SELECT
+    sqlc.embed(pt),
+    acs.name AS acs_name,
+    a.public_id AS area_public_id,
+    a.name AS area_name
+FROM practice_tests pt
+    JOIN acs ON pt.acs_id = acs.id
+    LEFT JOIN acs_areas a ON pt.area_id = a.id
+WHERE pt.id = $1 AND pt.user_id = $2;
+
+-- name: ListPracticeTestQuestions :many
+SELECT
+    sqlc.embed(q),
+    ptq.position,
+    ptq.choice,
+    ptq.correct
+FROM practice_test_questions ptq
+    JOIN questions q ON ptq.question_id = q.id
+WHERE ptq.test_id = $1
+ORDER BY ptq.position ASC;
+
+-- name: ListQuestionChoicesByQuestionIDs :many
+SELECT *
+FROM question_choices
+WHERE question_id = ANY ($1::int[])
+ORDER BY question_id ASC, "order" ASC;
+
+-- name: ListQuestionElementsByQuestionIDs :many
+SELECT *
+FROM question_elements
+WHERE question_id = ANY ($1::int[])
+ORDER BY question_id ASC, element_code ASC;
+
+-- name: AnswerPracticeTestQuestion :execrows
+UPDATE practice_test_questions ptq
+SET choice = sqlc.arg(choice)::int, correct = (q.answer = sqlc.arg(choice)::int), answered_at = now()
+FROM practice_tests pt, questions q
+WHERE ptq.test_id = pt.id
+    AND ptq.question_id = q.id
+    AND pt.completed_at IS NULL
+    AND ptq.test_id = sqlc.arg(test_id)
+    AND ptq.question_id = sqlc.arg(question_id);
+
+-- name: CompletePracticeTest :exec
+UPDATE practice_tests
+SET completed_at = now()
+WHERE id = $1 AND user_id = $2 AND completed_at IS NULL;
+
+-- name: ListQuestionStatsByElementIDs :many
+-- Answers count toward every element their question is tagged with, in every
+-- edition of the ACS.
+SELECT
+    e.id AS element_id,
+    COUNT(*) AS answered,
+    COUNT(*) FILTER (WHERE ptq.correct) AS correct
+FROM acs_elements e
+    JOIN acs_area_tasks t ON e.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+    JOIN acs ON a.acs_id = acs.id
+    JOIN question_elements qe
+        ON qe.element_code = (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)
+    JOIN practice_test_questions ptq ON qe.question_id = ptq.question_id
+    JOIN practice_tests pt ON ptq.test_id = pt.id
+WHERE e.id = ANY(sqlc.arg(element_ids)::int[])
+    AND pt.user_id = sqlc.arg(user_id)
+    AND ptq.answered_at IS NOT NULL
+GROUP BY e.id;
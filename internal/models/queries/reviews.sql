@@ -0,0 +1,38 @@
+-- name: GetElementReview :one
+SELECT *
+FROM element_reviews
+WHERE user_id = $1 AND element_id = $2
+FOR UPDATE;
+
+-- name: UpsertElementReview :exec
+INSERT INTO element_reviews (user_id, element_id, repetitions, interval_days, ease_factor, rated_at, due_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, element_id) DO UPDATE
+SET repetitions = EXCLUDED.repetitions,
+    interval_days = EXCLUDED.interval_days,
+    ease_factor = EXCLUDED.ease_factor,
+    rated_at = EXCLUDED.rated_at,
+    due_at = EXCLUDED.due_at;
+
+-- name: DeleteElementReview :exec
+DELETE FROM element_reviews
+WHERE user_id = $1 AND element_id = $2;
+
+-- name: ListDueElementReviews :many
+SELECT
+    sqlc.embed(e),
+    r.rated_at,
+    r.due_at,
+    c.vote AS confidence_vote,
+    a.acs_id AS acs_id,
+    a.public_id AS area_public_id,
+    t.public_id AS task_public_id,
+    (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id
+FROM element_reviews r
+    JOIN acs_elements e ON r.element_id = e.id
+    JOIN acs_area_tasks t ON e.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+    JOIN acs ON a.acs_id = acs.id
+    LEFT JOIN element_confidence c ON r.element_id = c.element_id AND r.user_id = c.user_id
+WHERE r.user_id = $1 AND r.due_at <= sqlc.arg(due_before)::timestamptz
+ORDER BY a.acs_id, a."order", t.public_id, e."type", e.public_id;
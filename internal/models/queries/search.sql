@@ -0,0 +1,77 @@
+-- name: Search :many
+-- Each matching task, reference, element, sub-element, or note is returned as
+-- its own row. Rows are grouped by task, with the tasks containing the best
+-- match first. The to_tsvector expressions must match the search indexes.
+WITH query AS (
+    SELECT websearch_to_tsquery('english', sqlc.arg(query)::text) AS q
+), matches AS (
+    SELECT
+        t.id AS task_id,
+        NULL::int AS element_id,
+        NULL::int AS sub_element_order,
+        'task'::text AS kind,
+        t."name" || ' ' || t.objective || ' ' || t.note AS document,
+        ts_rank(to_tsvector('english', t."name" || ' ' || t.objective || ' ' || t.note), query.q) AS rank
+    FROM acs_area_tasks t, query
+    WHERE to_tsvector('english', t."name" || ' ' || t.objective || ' ' || t.note) @@ query.q
+
+    UNION ALL
+
+    SELECT r.task_id, NULL, NULL, 'reference', r.document, ts_rank(to_tsvector('english', r.document), query.q)
+    FROM task_references r, query
+    WHERE to_tsvector('english', r.document) @@ query.q
+
+    UNION ALL
+
+    SELECT e.task_id, e.id, NULL, 'element', e.content, ts_rank(to_tsvector('english', e.content), query.q)
+    FROM acs_elements e, query
+    WHERE to_tsvector('english', e.content) @@ query.q
+
+    UNION ALL
+
+    SELECT e.task_id, e.id, s."order", 'sub-element', s.content, ts_rank(to_tsvector('english', s.content), query.q)
+    FROM acs_subelements s
+        JOIN acs_elements e ON s.element_id = e.id,
+        query
+    WHERE to_tsvector('english', s.content) @@ query.q
+
+    UNION ALL
+
+    SELECT e.task_id, e.id, NULL, 'note', n.content, ts_rank(to_tsvector('english', n.content), query.q)
+    FROM element_notes n
+        JOIN acs_elements e ON n.element_id = e.id,
+        query
+    WHERE n.user_id = sqlc.arg(user_id) AND to_tsvector('english', n.content) @@ query.q
+
+    UNION ALL
+
+    SELECT e.task_id, e.id, n.sub_element_order, 'note', n.content, ts_rank(to_tsvector('english', n.content), query.q)
+    FROM sub_element_notes n
+        JOIN acs_elements e ON n.element_id = e.id,
+        query
+    WHERE n.user_id = sqlc.arg(user_id) AND to_tsvector('english', n.content) @@ query.q
+)
+SELECT
+    sqlc.embed(t),
+    sqlc.embed(a),
+    m.element_id,
+    m.sub_element_order,
+    m.kind,
+    COALESCE(acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id, '')::text AS element_full_public_id,
+    -- Matches are wrapped in control characters rather than markup so that the
+    -- document can be escaped before it is displayed.
+    ts_headline(
+        'english',
+        m.document,
+        query.q,
+        'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=2, MaxWords=20, MinWords=8'
+    )::text AS snippet
+FROM matches m
+    JOIN acs_area_tasks t ON m.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+    JOIN acs ON a.acs_id = acs.id
+    LEFT JOIN acs_elements e ON m.element_id = e.id,
+    query
+WHERE sqlc.narg(acs_id)::text IS NULL OR a.acs_id = sqlc.narg(acs_id)::text
+ORDER BY max(m.rank) OVER (PARTITION BY t.id) DESC, t.id, m.rank DESC, e.type, e.public_id, m.sub_element_order
+LIMIT sqlc.arg(result_limit)::int;
@@ -0,0 +1,95 @@
+-- name: CreateStudySession :one
+INSERT INTO study_sessions (user_id, acs_id, area_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: PickStudySessionElements :execrows
+-- Elements are sampled without replacement, weighted toward low and unrated
+-- confidence. Sorting by -ln(u) / weight for a uniform random u gives a
+-- weighted random order (Efraimidis-Spirakis).
+INSERT INTO study_session_elements (session_id, element_id, position, before_vote)
+SELECT
+    sqlc.arg(session_id)::int,
+    picked.id,
+    row_number() OVER (ORDER BY picked.sort_key),
+    picked.vote
+FROM (
+    SELECT
+        e.id,
+        c.vote,
+        -ln(1.0 - random()) / (
+            CASE c.vote
+                WHEN 1 THEN 8
+                WHEN 2 THEN 3
+                WHEN 3 THEN 1
+                ELSE 6
+            END
+        ) AS sort_key
+    FROM acs_elements e
+        JOIN acs_area_tasks t ON e.task_id = t.id
+        JOIN acs_areas a ON t.area_id = a.id
+        LEFT JOIN element_confidence c ON e.id = c.element_id AND c.user_id = sqlc.arg(user_id)
+    WHERE a.acs_id = sqlc.arg(acs_id)
+        AND (sqlc.narg(area_id)::int IS NULL OR a.id = sqlc.narg(area_id)::int)
+        AND task_applies_to_user(t.applies_to, sqlc.arg(user_id))
+        AND task_applies_to_user(e.applies_to, sqlc.arg(user_id))
+    ORDER BY sort_key
+    LIMIT sqlc.arg(element_count)::int
+) picked;
+
+-- name: GetStudySession :one
+SELECT
+    sqlc.embed(s),
+    acs.name AS acs_name,
+    a.public_id AS area_public_id,
+    a.name AS area_name
+FROM study_sessions s
+    JOIN acs ON s.acs_id = acs.id
+    LEFT JOIN acs_areas a ON s.area_id = a.id
+WHERE s.id = $1 AND s.user_id = $2;
+
+-- name: ListStudySessionElements :many
+SELECT
+    sqlc.embed(e),
+    se.position,
+    se.before_vote,
+    se.after_vote,
+    c.vote AS confidence_vote,
+    r.due_at AS review_due_at,
+    EXISTS (
+        SELECT 1 FROM missed_elements m WHERE m.element_id = e.id AND m.user_id = sqlc.arg(user_id)
+    )::bool AS missed_on_written,
+    a.acs_id AS acs_id,
+    a.public_id AS area_public_id,
+    t.public_id AS task_public_id,
+    t.name AS task_name,
+    (acs.code || '.' || a.public_id || '.' || t.public_id || '.' || e.type || e.public_id)::text AS full_public_id
+FROM study_session_elements se
+    JOIN acs_elements e ON se.element_id = e.id
+    JOIN acs_area_tasks t ON e.task_id = t.id
+    JOIN acs_areas a ON t.area_id = a.id
+    JOIN acs ON a.acs_id = acs.id
+    LEFT JOIN element_confidence c ON e.id = c.element_id AND c.user_id = sqlc.arg(user_id)
+    LEFT JOIN element_reviews r ON e.id = r.element_id AND r.user_id = sqlc.arg(user_id)
+WHERE se.session_id = sqlc.arg(session_id)
+ORDER BY se.position ASC;
+
+-- name: ListTaskReferencesByTaskIDs :many
+SELECT *
+FROM task_references
+WHERE task_id = ANY ($1::int[])
+ORDER BY task_id ASC, "order" ASC;
+
+-- name: RateStudySessionElement :execrows
+UPDATE study_session_elements se
+SET after_vote = $3, rated_at = now()
+FROM study_sessions s
+WHERE se.session_id = s.id
+    AND s.completed_at IS NULL
+    AND se.session_id = $1
+    AND se.element_id = $2;
+
+-- name: CompleteStudySession :exec
+UPDATE study_sessions
+SET completed_at = now()
+WHERE id = $1 AND user_id = $2 AND completed_at IS NULL;
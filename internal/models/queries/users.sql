@@ -0,0 +1,98 @@
+-- name: InsertUser :one
+INSERT INTO users (username, password_hash)
+VALUES ($1, $2)
+RETURNING id;
+
+-- name: GetUserByID :one
+SELECT *
+FROM users
+WHERE id = $1;
+
+-- name: GetUserByUsername :one
+SELECT *
+FROM users
+WHERE username = $1;
+
+-- name: SetUserPasswordHash :execrows
+UPDATE users
+SET password_hash = $2
+WHERE username = $1;
+
+-- name: InsertAPIToken :one
+INSERT INTO api_tokens (user_id, "name", token_hash)
+VALUES ($1, $2, $3)
+RETURNING id;
+
+-- name: GetUserByAPITokenHash :one
+UPDATE api_tokens t
+SET last_used_at = now()
+FROM users u
+WHERE t.user_id = u.id AND t.token_hash = $1
+RETURNING sqlc.embed(u);
+
+-- name: ListAPITokensByUser :many
+SELECT id, "name", created_at, last_used_at
+FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens
+WHERE user_id = $1 AND id = $2;
+
+-- name: SetUserAdmin :execrows
+UPDATE users
+SET is_admin = $2
+WHERE username = $1;
+
+-- name: SetUserRole :one
+UPDATE users
+SET "role" = $2
+WHERE username = $1
+RETURNING id;
+
+-- name: AssignStudent :exec
+INSERT INTO instructor_students (instructor_id, student_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: UnassignStudent :execrows
+DELETE FROM instructor_students
+WHERE instructor_id = $1 AND student_id = $2;
+
+-- name: UnassignAllStudents :exec
+DELETE FROM instructor_students
+WHERE instructor_id = $1;
+
+-- name: ListStudents :many
+-- A student's last activity is the most recent time they rated an element, edited a note, or
+-- started a study session.
+SELECT
+    sqlc.embed(u),
+    GREATEST(
+        (SELECT MAX(ev.created_at) FROM confidence_events ev WHERE ev.user_id = u.id),
+        (SELECT MAX(n.updated_at) FROM element_notes n WHERE n.user_id = u.id),
+        (SELECT MAX(n.updated_at) FROM sub_element_notes n WHERE n.user_id = u.id),
+        (SELECT MAX(s.created_at) FROM study_sessions s WHERE s.user_id = u.id)
+    )::timestamptz AS last_active_at
+FROM instructor_students i
+    JOIN users u ON i.student_id = u.id
+WHERE i.instructor_id = $1
+ORDER BY u.username ASC;
+
+-- name: GetStudent :one
+SELECT sqlc.embed(u)
+FROM instructor_students i
+    JOIN users u ON i.student_id = u.id
+WHERE i.instructor_id = $1 AND i.student_id = $2;
+
+-- name: GetStudentByUsername :one
+SELECT sqlc.embed(u)
+FROM instructor_students i
+    JOIN users u ON i.student_id = u.id
+WHERE i.instructor_id = $1 AND u.username = $2;
+
+-- name: SetUserClassRating :exec
+UPDATE users
+SET class_rating = $2
+WHERE id = $1;
@@ -0,0 +1,346 @@
+package models
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+// QuestionBank is a file of knowledge test questions to import.
+type QuestionBank struct {
+	Questions []ExternalQuestion `json:"questions"`
+}
+
+// ExternalQuestion is a multiple choice question as it appears in an imported file.
+type ExternalQuestion struct {
+	// ID identifies the question within the bank so that importing the bank again updates it.
+	ID          string   `json:"id"`
+	Question    string   `json:"question"`
+	Choices     []string `json:"choices"`
+	Answer      string   `json:"answer"`
+	Explanation string   `json:"explanation,omitempty"`
+
+	// Elements lists the codes of the elements the question tests, e.g. PA.I.B.K3.
+	Elements []string `json:"elements"`
+}
+
+// QuestionImport summarizes the result of importing a question bank.
+type QuestionImport struct {
+	Questions int
+
+	// Unknown lists the element codes that don't match an element in any loaded ACS. Questions
+	// tagged with them are still imported in case the ACS is loaded later.
+	Unknown []string
+}
+
+const (
+	maxQuestionIDLength          = 50
+	maxQuestionLength            = 2000
+	maxQuestionExplanationLength = 5000
+	maxQuestionChoices           = 26
+)
+
+// elementCodePattern matches the code of a single element, such as "PA.I.B.K3".
+var elementCodePattern = regexp.MustCompile(`^[A-Z]{2}\.[IVX]+\.[A-Z]\.[KRSE]\d+$`)
+
+// choiceLetter returns the letter a choice is labeled with.
+func choiceLetter(order int32) string {
+	return string(rune('A' + order))
+}
+
+// parseChoiceLetter converts a choice's letter into its position.
+func parseChoiceLetter(letter string) (int32, error) {
+	letter = strings.ToUpper(strings.TrimSpace(letter))
+	if len(letter) != 1 || letter[0] < 'A' || letter[0] > 'Z' {
+		return 0, fmt.Errorf("invalid choice %q", letter)
+	}
+
+	return int32(letter[0] - 'A'), nil
+}
+
+// ValidateQuestionBank checks every question in a bank before anything is imported so that a bad
+// file doesn't leave a partial import behind.
+func ValidateQuestionBank(bank QuestionBank) error {
+	seen := make(map[string]bool, len(bank.Questions))
+	for i, q := range bank.Questions {
+		if q.ID == "" {
+			return fmt.Errorf("question %d: missing ID", i+1)
+		}
+
+		if len(q.ID) > maxQuestionIDLength {
+			return fmt.Errorf("%s: ID is longer than %d characters", q.ID, maxQuestionIDLength)
+		}
+
+		if seen[q.ID] {
+			return fmt.Errorf("%s: duplicate question ID", q.ID)
+		}
+
+		seen[q.ID] = true
+
+		if strings.TrimSpace(q.Question) == "" {
+			return fmt.Errorf("%s: missing question", q.ID)
+		}
+
+		if len([]rune(q.Question)) > maxQuestionLength {
+			return fmt.Errorf("%s: question is longer than %d characters", q.ID, maxQuestionLength)
+		}
+
+		if len([]rune(q.Explanation)) > maxQuestionExplanationLength {
+			return fmt.Errorf("%s: explanation is longer than %d characters", q.ID, maxQuestionExplanationLength)
+		}
+
+		if len(q.Choices) < 2 || len(q.Choices) > maxQuestionChoices {
+			return fmt.Errorf("%s: questions need between 2 and %d choices", q.ID, maxQuestionChoices)
+		}
+
+		if slices.ContainsFunc(q.Choices, func(c string) bool { return strings.TrimSpace(c) == "" }) {
+			return fmt.Errorf("%s: choices can't be empty", q.ID)
+		}
+
+		answer, err := parseChoiceLetter(q.Answer)
+		if err != nil {
+			return fmt.Errorf("%s: %v", q.ID, err)
+		}
+
+		if int(answer) >= len(q.Choices) {
+			return fmt.Errorf("%s: answer %s is not one of the choices", q.ID, q.Answer)
+		}
+
+		if len(q.Elements) == 0 {
+			return fmt.Errorf("%s: questions must be tagged with at least one element", q.ID)
+		}
+
+		for _, code := range q.Elements {
+			if !elementCodePattern.MatchString(code) {
+				return fmt.Errorf("%s: invalid element code %q", q.ID, code)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ParseQuestionCSV reads a question bank from CSV. The first row is a header naming the columns:
+// id, question, answer, explanation, elements, and one column per choice labeled with its letter
+// (a, b, c, ...). Elements are separated by spaces, commas, or semicolons. Choices that are left
+// empty are dropped so questions can have fewer choices than there are columns.
+func ParseQuestionCSV(r io.Reader) (QuestionBank, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return QuestionBank{}, fmt.Errorf("failed to read header: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	var choiceColumns []int
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		columns[name] = i
+
+		if len(name) == 1 && name[0] >= 'a' && name[0] <= 'z' {
+			choiceColumns = append(choiceColumns, i)
+		}
+	}
+
+	for _, required := range []string{"id", "question", "answer", "elements"} {
+		if _, ok := columns[required]; !ok {
+			return QuestionBank{}, fmt.Errorf("missing %q column", required)
+		}
+	}
+
+	slices.SortFunc(choiceColumns, func(a, b int) int {
+		return strings.Compare(strings.ToLower(strings.TrimSpace(header[a])), strings.ToLower(strings.TrimSpace(header[b])))
+	})
+
+	bank := QuestionBank{Questions: make([]ExternalQuestion, 0)}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return QuestionBank{}, fmt.Errorf("failed to read questions: %v", err)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+
+			return strings.TrimSpace(record[i])
+		}
+
+		q := ExternalQuestion{
+			ID:          field("id"),
+			Question:    field("question"),
+			Answer:      field("answer"),
+			Explanation: field("explanation"),
+			Elements: strings.FieldsFunc(field("elements"), func(r rune) bool {
+				return unicode.IsSpace(r) || r == ',' || r == ';'
+			}),
+		}
+
+		for _, i := range choiceColumns {
+			if i < len(record) && strings.TrimSpace(record[i]) != "" {
+				q.Choices = append(q.Choices, strings.TrimSpace(record[i]))
+			}
+		}
+
+		bank.Questions = append(bank.Questions, q)
+	}
+
+	return bank, nil
+}
+
+// ImportQuestions adds the questions in a bank, replacing the content of questions that were
+// imported before with the same ID. Questions that aren't in the bank are left alone.
+func (m *ACSModel) ImportQuestions(ctx context.Context, bank QuestionBank) (QuestionImport, error) {
+	if err := ValidateQuestionBank(bank); err != nil {
+		return QuestionImport{}, err
+	}
+
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return QuestionImport{}, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback question import transaction.", "error", err)
+		}
+	}()
+
+	q := queries.New(tx)
+
+	var codes []string
+	for _, question := range bank.Questions {
+		for _, code := range question.Elements {
+			if !slices.Contains(codes, code) {
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	known, err := q.ListKnownElementCodes(ctx, codes)
+	if err != nil {
+		return QuestionImport{}, fmt.Errorf("failed to resolve element codes: %v", err)
+	}
+
+	result := QuestionImport{Unknown: make([]string, 0)}
+	for _, code := range codes {
+		if !slices.Contains(known, code) {
+			result.Unknown = append(result.Unknown, code)
+		}
+	}
+
+	for _, question := range bank.Questions {
+		if err := importQuestion(ctx, q, question); err != nil {
+			return QuestionImport{}, fmt.Errorf("failed to import question %s: %v", question.ID, err)
+		}
+
+		result.Questions++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return QuestionImport{}, fmt.Errorf("failed to commit question import: %v", err)
+	}
+
+	m.logger.InfoContext(ctx, "Imported questions.", "questions", result.Questions, "unknownElements", len(result.Unknown))
+
+	return result, nil
+}
+
+func importQuestion(ctx context.Context, q *queries.Queries, question ExternalQuestion) error {
+	answer, _ := parseChoiceLetter(question.Answer)
+
+	questionID, err := q.UpsertQuestion(ctx, queries.UpsertQuestionParams{
+		PublicID:    question.ID,
+		Prompt:      strings.TrimSpace(question.Question),
+		Explanation: strings.TrimSpace(question.Explanation),
+		Answer:      answer,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, choice := range question.Choices {
+		err := q.UpsertQuestionChoice(ctx, queries.UpsertQuestionChoiceParams{
+			QuestionID: questionID,
+			Order:      int32(i),
+			Content:    strings.TrimSpace(choice),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save choice %s: %v", choiceLetter(int32(i)), err)
+		}
+	}
+
+	err = q.ClearExtraQuestionChoices(ctx, queries.ClearExtraQuestionChoicesParams{
+		QuestionID:  questionID,
+		ChoiceCount: int32(len(question.Choices)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear old choices: %v", err)
+	}
+
+	if err := q.ClearQuestionElements(ctx, questionID); err != nil {
+		return fmt.Errorf("failed to clear old elements: %v", err)
+	}
+
+	elements := slices.Compact(slices.Sorted(slices.Values(question.Elements)))
+	err = q.AddQuestionElements(ctx, queries.AddQuestionElementsParams{QuestionID: questionID, ElementCodes: elements})
+	if err != nil {
+		return fmt.Errorf("failed to tag elements: %v", err)
+	}
+
+	return nil
+}
+
+// QuestionStats summarizes a user's answers to the practice questions about an element.
+type QuestionStats struct {
+	Answered int
+	Correct  int
+}
+
+// attachQuestionStats adds the user's practice test results to each element.
+func (m *ACSModel) attachQuestionStats(ctx context.Context, userID int32, elements []TaskElement) error {
+	if len(elements) == 0 {
+		return nil
+	}
+
+	elementIDs := make([]int32, len(elements))
+	for i, e := range elements {
+		elementIDs[i] = e.ID
+	}
+
+	rows, err := m.q.ListQuestionStatsByElementIDs(ctx, queries.ListQuestionStatsByElementIDsParams{
+		ElementIds: elementIDs,
+		UserID:     userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list question stats: %v", err)
+	}
+
+	stats := make(map[int32]QuestionStats, len(rows))
+	for _, row := range rows {
+		stats[row.ElementID] = QuestionStats{Answered: int(row.Answered), Correct: int(row.Correct)}
+	}
+
+	for i := range elements {
+		elements[i].Questions = stats[elements[i].ID]
+	}
+
+	return nil
+}
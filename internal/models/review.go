@@ -0,0 +1,175 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ReviewSchedule tracks when an element should next be reviewed using the SM-2 spaced repetition
+// algorithm.
+type ReviewSchedule struct {
+	Repetitions  int
+	IntervalDays int
+	EaseFactor   float64
+	DueAt        time.Time
+}
+
+const (
+	initialEaseFactor = 2.5
+	minimumEaseFactor = 1.3
+)
+
+// reviewQuality maps a confidence level onto SM-2's 0-5 recall quality scale. Low confidence is
+// treated as a failed recall.
+func reviewQuality(level ConfidenceLevel) int {
+	switch level {
+	case ConfidenceLevelHigh:
+		return 5
+
+	case ConfidenceLevelMedium:
+		return 3
+	}
+
+	return 1
+}
+
+// nextReview computes the schedule for an element after it is rated. The previous schedule should
+// be the zero value if the element has never been rated.
+func nextReview(previous ReviewSchedule, level ConfidenceLevel, now time.Time) ReviewSchedule {
+	easeFactor := previous.EaseFactor
+	if easeFactor == 0 {
+		easeFactor = initialEaseFactor
+	}
+
+	quality := reviewQuality(level)
+
+	next := ReviewSchedule{}
+	if quality < 3 {
+		next.Repetitions = 0
+		next.IntervalDays = 1
+	} else {
+		switch previous.Repetitions {
+		case 0:
+			next.IntervalDays = 1
+		case 1:
+			next.IntervalDays = 6
+		default:
+			next.IntervalDays = int(math.Round(float64(previous.IntervalDays) * easeFactor))
+		}
+
+		next.Repetitions = previous.Repetitions + 1
+	}
+
+	penalty := float64(5 - quality)
+	next.EaseFactor = max(minimumEaseFactor, easeFactor+0.1-penalty*(0.08+penalty*0.02))
+	next.DueAt = now.AddDate(0, 0, next.IntervalDays)
+
+	return next
+}
+
+// ReviewItem is a rated element along with its review schedule.
+type ReviewItem struct {
+	ElementID       int32
+	FullPublicID    string
+	Content         string
+	ConfidenceLevel *ConfidenceLevel
+
+	ACS          string
+	AreaPublicID string
+	TaskPublicID string
+
+	// RatedAt is the last time the element was rated. It is nil for votes that predate review
+	// tracking.
+	RatedAt *time.Time
+	DueAt   time.Time
+}
+
+// Path returns the URL path of the element within its task's page.
+func (r ReviewItem) Path() string {
+	return fmt.Sprintf("/acs/%s/%s/%s#%s", r.ACS, r.AreaPublicID, r.TaskPublicID, r.FullPublicID)
+}
+
+// ListDueReviews returns the elements a user should review as of a particular time, in ACS order.
+func (m *ACSModel) ListDueReviews(ctx context.Context, userID int32, asOf time.Time) ([]ReviewItem, error) {
+	rows, err := m.q.ListDueElementReviews(ctx, queries.ListDueElementReviewsParams{
+		UserID:    userID,
+		DueBefore: pgtype.Timestamptz{Time: asOf, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due reviews for user %d: %v", userID, err)
+	}
+
+	items := make([]ReviewItem, len(rows))
+	for i, row := range rows {
+		item := ReviewItem{
+			ElementID:    row.AcsElement.ID,
+			FullPublicID: row.FullPublicID,
+			Content:      row.AcsElement.Content,
+			ACS:          row.AcsID,
+			AreaPublicID: row.AreaPublicID,
+			TaskPublicID: row.TaskPublicID,
+			DueAt:        row.DueAt.Time,
+		}
+
+		if row.ConfidenceVote.Valid {
+			level := ConfidenceLevel(row.ConfidenceVote.Int16)
+			item.ConfidenceLevel = &level
+		}
+
+		if row.RatedAt.Valid {
+			item.RatedAt = &row.RatedAt.Time
+		}
+
+		items[i] = item
+	}
+
+	return items, nil
+}
+
+// scheduleReview updates the review schedule for an element that was just rated.
+func scheduleReview(
+	ctx context.Context,
+	q *queries.Queries,
+	userID int32,
+	elementID int32,
+	level ConfidenceLevel,
+	now time.Time,
+) (ReviewSchedule, error) {
+	var previous ReviewSchedule
+
+	existing, err := q.GetElementReview(ctx, queries.GetElementReviewParams{UserID: userID, ElementID: elementID})
+	if err == nil {
+		previous = ReviewSchedule{
+			Repetitions:  int(existing.Repetitions),
+			IntervalDays: int(existing.IntervalDays),
+			EaseFactor:   float64(existing.EaseFactor),
+			DueAt:        existing.DueAt.Time,
+		}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return ReviewSchedule{}, fmt.Errorf("failed to retrieve existing review schedule: %v", err)
+	}
+
+	next := nextReview(previous, level, now)
+
+	err = q.UpsertElementReview(ctx, queries.UpsertElementReviewParams{
+		UserID:       userID,
+		ElementID:    elementID,
+		Repetitions:  int32(next.Repetitions),
+		IntervalDays: int32(next.IntervalDays),
+		EaseFactor:   float32(next.EaseFactor),
+		RatedAt:      pgtype.Timestamptz{Time: now, Valid: true},
+		DueAt:        pgtype.Timestamptz{Time: next.DueAt, Valid: true},
+	})
+	if err != nil {
+		return ReviewSchedule{}, fmt.Errorf("failed to save review schedule: %v", err)
+	}
+
+	return next, nil
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MaxSearchResults caps the number of matches returned by a search.
+const MaxSearchResults = 100
+
+// Markers placed around matched terms by the search query's headline options.
+const (
+	searchMatchStart = "\x02"
+	searchMatchStop  = "\x03"
+)
+
+// SearchMatchKind describes what part of a task a search matched.
+type SearchMatchKind string
+
+const (
+	SearchMatchTask       SearchMatchKind = "task"
+	SearchMatchReference  SearchMatchKind = "reference"
+	SearchMatchElement    SearchMatchKind = "element"
+	SearchMatchSubElement SearchMatchKind = "sub-element"
+	SearchMatchNote       SearchMatchKind = "note"
+)
+
+// SnippetPart is a piece of a search snippet. Parts that matched the query are flagged so they can
+// be highlighted.
+type SnippetPart struct {
+	Text  string
+	Match bool
+}
+
+// SearchMatch is a single piece of content that matched a search.
+type SearchMatch struct {
+	Kind SearchMatchKind
+
+	// ElementFullPublicID identifies the element the match belongs to. It is empty for matches on
+	// the task itself or its references.
+	ElementFullPublicID string
+
+	// SubElementOrder is set for matches on a sub-element or a note attached to one.
+	SubElementOrder *int32
+
+	Snippet []SnippetPart
+}
+
+// Location returns the public ID of the element or sub-element the match belongs to, or an empty
+// string for task-level matches.
+func (m SearchMatch) Location() string {
+	if m.SubElementOrder != nil {
+		return m.ElementFullPublicID + SubElement{Order: *m.SubElementOrder}.PublicID()
+	}
+
+	return m.ElementFullPublicID
+}
+
+// SearchResult groups the matches belonging to a single task.
+type SearchResult struct {
+	Task    Task
+	Matches []SearchMatch
+}
+
+// Search finds tasks, references, elements, and sub-elements containing the query, along with the
+// user's own notes. The query accepts web search syntax such as quoted phrases and "-" to exclude
+// terms. If acs is not empty, results are limited to that ACS.
+func (m *ACSModel) Search(ctx context.Context, userID int32, query string, acs string) ([]SearchResult, error) {
+	rows, err := m.q.Search(ctx, queries.SearchParams{
+		AcsID:       pgtype.Text{String: acs, Valid: acs != ""},
+		ResultLimit: MaxSearchResults,
+		Query:       query,
+		UserID:      userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for %q: %v", query, err)
+	}
+
+	results := make([]SearchResult, 0)
+	for _, row := range rows {
+		if len(results) == 0 || results[len(results)-1].Task.ID != row.Task.ID {
+			results = append(results, SearchResult{
+				Task: Task{
+					ID:        row.Task.ID,
+					PublicID:  row.Task.PublicID,
+					Name:      row.Task.Name,
+					Objective: row.Task.Objective,
+					Note:      row.Task.Note,
+					Area:      areaOfOperationFromModel(row.AcsArea),
+				},
+			})
+		}
+
+		match := SearchMatch{
+			Kind:                SearchMatchKind(row.Kind),
+			ElementFullPublicID: row.ElementFullPublicID,
+			Snippet:             parseSnippet(row.Snippet),
+		}
+
+		if row.SubElementOrder.Valid {
+			match.SubElementOrder = &row.SubElementOrder.Int32
+		}
+
+		result := &results[len(results)-1]
+		result.Matches = append(result.Matches, match)
+	}
+
+	return results, nil
+}
+
+// parseSnippet splits a headline generated by the search query into highlighted and plain parts.
+func parseSnippet(headline string) []SnippetPart {
+	parts := make([]SnippetPart, 0, 1)
+	for {
+		before, rest, found := strings.Cut(headline, searchMatchStart)
+		if before != "" {
+			parts = append(parts, SnippetPart{Text: before})
+		}
+
+		if !found {
+			return parts
+		}
+
+		match, after, _ := strings.Cut(rest, searchMatchStop)
+		if match != "" {
+			parts = append(parts, SnippetPart{Text: match, Match: true})
+		}
+
+		headline = after
+	}
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// SignOff records an instructor's judgement that a student demonstrated an element satisfactorily.
+// It is independent of the student's own confidence rating.
+type SignOff struct {
+	InstructorUsername string
+	SignedOffAt        time.Time
+}
+
+func signOffFromModel(instructor pgtype.Text, signedOffAt pgtype.Timestamptz) *SignOff {
+	if !signedOffAt.Valid {
+		return nil
+	}
+
+	return &SignOff{InstructorUsername: instructor.String, SignedOffAt: signedOffAt.Time}
+}
+
+// Readiness counts how many of a group's elements an instructor has signed off.
+type Readiness struct {
+	SignedOff int
+	Elements  int
+}
+
+// Ready indicates every element in the group has been signed off.
+func (r Readiness) Ready() bool {
+	return r.Elements > 0 && r.SignedOff == r.Elements
+}
+
+// Readiness counts the task's elements that have been signed off.
+func (t Task) Readiness() Readiness {
+	var readiness Readiness
+	for _, group := range [][]TaskElement{t.KnowledgeElements, t.RiskManagementElements, t.SkillElements, t.PTSElements} {
+		for _, element := range group {
+			readiness.Elements++
+			if element.SignOff != nil {
+				readiness.SignedOff++
+			}
+		}
+	}
+
+	return readiness
+}
+
+// SignOffElement records that an instructor saw a student demonstrate an element satisfactorily.
+// Signing off an element again replaces the earlier sign-off.
+func (m *ACSModel) SignOffElement(ctx context.Context, instructorID int32, studentID int32, elementID int32) error {
+	err := m.q.SignOffElement(ctx, queries.SignOffElementParams{
+		StudentID:    studentID,
+		ElementID:    elementID,
+		InstructorID: instructorID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign off element %d for user %d: %v", elementID, studentID, err)
+	}
+
+	m.logger.InfoContext(
+		ctx,
+		"Signed off element.",
+		"instructorID", instructorID,
+		"studentID", studentID,
+		"elementID", elementID,
+	)
+
+	return nil
+}
+
+// RemoveElementSignOff removes a student's sign-off for an element, if there is one.
+func (m *ACSModel) RemoveElementSignOff(ctx context.Context, studentID int32, elementID int32) error {
+	err := m.q.RemoveElementSignOff(ctx, queries.RemoveElementSignOffParams{
+		StudentID: studentID,
+		ElementID: elementID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove sign-off of element %d for user %d: %v", elementID, studentID, err)
+	}
+
+	m.logger.InfoContext(ctx, "Removed element sign-off.", "studentID", studentID, "elementID", elementID)
+
+	return nil
+}
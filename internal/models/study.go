@@ -0,0 +1,416 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrNothingToStudy is returned when a study session would not contain any elements.
+var ErrNothingToStudy = errors.New("models: no elements to study")
+
+// StudySession is a sample of elements from an ACS or one of its areas that a user rates one at a
+// time.
+type StudySession struct {
+	ID      int32
+	ACS     string
+	ACSName string
+
+	// Area is only set for sessions limited to a single area of operation.
+	Area *AreaOfOperation
+
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+
+	Elements []StudyElement
+}
+
+// Scope returns the full public ID of the ACS or area the session covers.
+func (s StudySession) Scope() string {
+	if s.Area != nil {
+		return s.Area.FullID()
+	}
+
+	return s.ACS
+}
+
+// Current returns the first element that hasn't been rated during the session. It returns nil
+// once every element is rated or the session has ended.
+func (s StudySession) Current() *StudyElement {
+	if s.CompletedAt != nil {
+		return nil
+	}
+
+	for i := range s.Elements {
+		if s.Elements[i].After == nil {
+			return &s.Elements[i]
+		}
+	}
+
+	return nil
+}
+
+// RatedCount returns the number of elements rated during the session.
+func (s StudySession) RatedCount() int {
+	count := 0
+	for _, e := range s.Elements {
+		if e.After != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Improved lists the rated elements whose confidence went up.
+func (s StudySession) Improved() []StudyElement {
+	return s.filterRated(func(e StudyElement) bool { return e.Change() > 0 })
+}
+
+// Declined lists the rated elements whose confidence went down.
+func (s StudySession) Declined() []StudyElement {
+	return s.filterRated(func(e StudyElement) bool { return e.Change() < 0 })
+}
+
+// Unchanged lists the rated elements whose confidence stayed the same.
+func (s StudySession) Unchanged() []StudyElement {
+	return s.filterRated(func(e StudyElement) bool { return e.Change() == 0 })
+}
+
+func (s StudySession) filterRated(keep func(StudyElement) bool) []StudyElement {
+	var elements []StudyElement
+	for _, e := range s.Elements {
+		if e.After != nil && keep(e) {
+			elements = append(elements, e)
+		}
+	}
+
+	return elements
+}
+
+// StudyElement is an element presented during a study session along with the context needed to
+// study it.
+type StudyElement struct {
+	Element  TaskElement
+	Position int
+
+	ACS          string
+	AreaPublicID string
+	TaskPublicID string
+	TaskName     string
+	References   []string
+
+	// Before is the user's confidence when the session started, and After is the rating given
+	// during the session. Either may be nil.
+	Before *ConfidenceLevel
+	After  *ConfidenceLevel
+}
+
+// TaskPath returns the URL path of the element within its task's page.
+func (e StudyElement) TaskPath() string {
+	return fmt.Sprintf("/acs/%s/%s/%s#%s", e.ACS, e.AreaPublicID, e.TaskPublicID, e.Element.FullPublicID)
+}
+
+// Change compares the rating given during the session with the confidence before it. Unrated
+// elements count as having no confidence, so any rating is an improvement.
+func (e StudyElement) Change() int {
+	var before, after int
+	if e.Before != nil {
+		before = int(*e.Before)
+	}
+
+	if e.After != nil {
+		after = int(*e.After)
+	}
+
+	return after - before
+}
+
+// StartStudySession picks up to count elements from an ACS, or one of its areas if areaPublicID is
+// not empty, and creates a session to study them. Elements with low or no confidence are more
+// likely to be picked.
+func (m *ACSModel) StartStudySession(
+	ctx context.Context,
+	userID int32,
+	acsID string,
+	areaPublicID string,
+	count int,
+) (int32, error) {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback study session transaction.", "error", err)
+		}
+	}()
+
+	q := queries.New(tx)
+
+	var areaID pgtype.Int4
+	if areaPublicID != "" {
+		area, err := q.GetAreaByPublicID(ctx, queries.GetAreaByPublicIDParams{AcsID: acsID, PublicID: areaPublicID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, newNotFoundError("area", acsID+"."+areaPublicID, err)
+			}
+
+			return 0, fmt.Errorf("failed to retrieve area %s.%s: %v", acsID, areaPublicID, err)
+		}
+
+		areaID = pgtype.Int4{Int32: area.ID, Valid: true}
+	}
+
+	session, err := q.CreateStudySession(ctx, queries.CreateStudySessionParams{
+		UserID: userID,
+		AcsID:  acsID,
+		AreaID: areaID,
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return 0, newNotFoundError("ACS", acsID, err)
+		}
+
+		return 0, fmt.Errorf("failed to create study session: %v", err)
+	}
+
+	picked, err := q.PickStudySessionElements(ctx, queries.PickStudySessionElementsParams{
+		SessionID:    session.ID,
+		UserID:       userID,
+		AcsID:        acsID,
+		AreaID:       areaID,
+		ElementCount: int32(count),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to pick elements for study session: %v", err)
+	}
+
+	if picked == 0 {
+		return 0, ErrNothingToStudy
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit study session: %v", err)
+	}
+
+	m.logger.InfoContext(ctx, "Started study session.", "userID", userID, "sessionID", session.ID, "elements", picked)
+
+	return session.ID, nil
+}
+
+// GetStudySession retrieves one of a user's study sessions with its elements in the order they
+// are presented.
+func (m *ACSModel) GetStudySession(ctx context.Context, userID int32, sessionID int32) (StudySession, error) {
+	row, err := m.q.GetStudySession(ctx, queries.GetStudySessionParams{ID: sessionID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return StudySession{}, newNotFoundError("study session", sessionID, err)
+		}
+
+		return StudySession{}, fmt.Errorf("failed to retrieve study session %d: %v", sessionID, err)
+	}
+
+	session := StudySession{
+		ID:        row.StudySession.ID,
+		ACS:       row.StudySession.AcsID,
+		ACSName:   row.AcsName,
+		CreatedAt: row.StudySession.CreatedAt.Time,
+	}
+
+	if row.StudySession.AreaID.Valid {
+		session.Area = &AreaOfOperation{
+			ID:       row.StudySession.AreaID.Int32,
+			ACS:      row.StudySession.AcsID,
+			PublicID: row.AreaPublicID.String,
+			Name:     row.AreaName.String,
+		}
+	}
+
+	if row.StudySession.CompletedAt.Valid {
+		session.CompletedAt = &row.StudySession.CompletedAt.Time
+	}
+
+	elements, err := m.q.ListStudySessionElements(ctx, queries.ListStudySessionElementsParams{
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return StudySession{}, fmt.Errorf("failed to list elements for study session %d: %v", sessionID, err)
+	}
+
+	elementIDs := make([]int32, len(elements))
+	taskIDs := make([]int32, len(elements))
+	for i, e := range elements {
+		elementIDs[i] = e.AcsElement.ID
+		taskIDs[i] = e.AcsElement.TaskID
+	}
+
+	subElements, err := m.listSubElements(ctx, userID, elementIDs)
+	if err != nil {
+		return StudySession{}, fmt.Errorf("failed to list sub-elements for study session: %v", err)
+	}
+
+	references, err := m.q.ListTaskReferencesByTaskIDs(ctx, taskIDs)
+	if err != nil {
+		return StudySession{}, fmt.Errorf("failed to list references for study session: %v", err)
+	}
+
+	referencesByTask := make(map[int32][]string)
+	for _, r := range references {
+		referencesByTask[r.TaskID] = append(referencesByTask[r.TaskID], r.Document)
+	}
+
+	session.Elements = make([]StudyElement, len(elements))
+	for i, e := range elements {
+		element := TaskElement{
+			ID:              e.AcsElement.ID,
+			TaskID:          e.AcsElement.TaskID,
+			Type:            taskElementTypeFromModel(e.AcsElement.Type),
+			PublicID:        e.AcsElement.PublicID,
+			Content:         e.AcsElement.Content,
+			FullPublicID:    e.FullPublicID,
+			SubElements:     subElements[e.AcsElement.ID],
+			MissedOnWritten: e.MissedOnWritten,
+		}
+
+		if e.ConfidenceVote.Valid {
+			level := ConfidenceLevel(e.ConfidenceVote.Int16)
+			element.ConfidenceLevel = &level
+		}
+
+		if e.ReviewDueAt.Valid {
+			element.ReviewDueAt = &e.ReviewDueAt.Time
+		}
+
+		studyElement := StudyElement{
+			Element:      element,
+			Position:     int(e.Position),
+			ACS:          e.AcsID,
+			AreaPublicID: e.AreaPublicID,
+			TaskPublicID: e.TaskPublicID,
+			TaskName:     e.TaskName,
+			References:   referencesByTask[e.AcsElement.TaskID],
+		}
+
+		if e.BeforeVote.Valid {
+			level := ConfidenceLevel(e.BeforeVote.Int16)
+			studyElement.Before = &level
+		}
+
+		if e.AfterVote.Valid {
+			level := ConfidenceLevel(e.AfterVote.Int16)
+			studyElement.After = &level
+		}
+
+		session.Elements[i] = studyElement
+	}
+
+	studied := make([]TaskElement, len(session.Elements))
+	for i, e := range session.Elements {
+		studied[i] = e.Element
+	}
+
+	if err := m.attachNotes(ctx, userID, studied); err != nil {
+		return StudySession{}, err
+	}
+
+	for i := range session.Elements {
+		session.Elements[i].Element = studied[i]
+	}
+
+	return session, nil
+}
+
+// RateStudyElement records the user's confidence in an element during a study session. The rating
+// is saved and scheduled for review just like one given from the task page. The session is
+// completed once every element has been rated.
+func (m *ACSModel) RateStudyElement(
+	ctx context.Context,
+	userID int32,
+	sessionID int32,
+	elementID int32,
+	confidence ConfidenceLevel,
+) error {
+	// Checking ownership up front keeps users from rating elements through someone else's session.
+	session, err := m.GetStudySession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Error("Failed to rollback study session transaction.", "error", err)
+		}
+	}()
+
+	q := queries.New(tx)
+
+	updated, err := q.RateStudySessionElement(ctx, queries.RateStudySessionElementParams{
+		SessionID: session.ID,
+		ElementID: elementID,
+		AfterVote: pgtype.Int2{Int16: int16(confidence), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rate element %d in study session %d: %v", elementID, sessionID, err)
+	}
+
+	if updated == 0 {
+		return newNotFoundError("study session element", fmt.Sprintf("%d/%d", sessionID, elementID), pgx.ErrNoRows)
+	}
+
+	if _, err := recordElementConfidence(ctx, q, userID, elementID, confidence, time.Now()); err != nil {
+		return err
+	}
+
+	// The element being rated is still unrated in the snapshot taken above, so the session is
+	// finished if it was the only one left.
+	remaining := len(session.Elements) - session.RatedCount()
+	if current := session.Current(); remaining == 1 && current != nil && current.Element.ID == elementID {
+		if err := q.CompleteStudySession(ctx, queries.CompleteStudySessionParams{ID: sessionID, UserID: userID}); err != nil {
+			return fmt.Errorf("failed to complete study session %d: %v", sessionID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit study session rating: %v", err)
+	}
+
+	m.logger.InfoContext(
+		ctx,
+		"Rated element in study session.",
+		"userID", userID,
+		"sessionID", sessionID,
+		"elementID", elementID,
+		"confidence", confidence,
+	)
+
+	return nil
+}
+
+// EndStudySession completes a study session early. Elements that weren't rated are left out of
+// the summary.
+func (m *ACSModel) EndStudySession(ctx context.Context, userID int32, sessionID int32) error {
+	if _, err := m.GetStudySession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	if err := m.q.CompleteStudySession(ctx, queries.CompleteStudySessionParams{ID: sessionID, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to complete study session %d: %v", sessionID, err)
+	}
+
+	m.logger.InfoContext(ctx, "Ended study session.", "userID", userID, "sessionID", sessionID)
+
+	return nil
+}
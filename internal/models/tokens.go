@@ -0,0 +1,114 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+)
+
+// apiTokenPrefix makes tokens easy to recognize, e.g. by secret scanners.
+const apiTokenPrefix = "fs_"
+
+// ErrInvalidAPIToken is returned when a bearer token does not belong to any user.
+var ErrInvalidAPIToken = errors.New("models: invalid API token")
+
+type APIToken struct {
+	ID         int32
+	Name       string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+func hashAPIToken(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+
+	return hash[:]
+}
+
+// CreateAPIToken generates a new API token for a user. The returned token is the only copy; only
+// its hash is stored.
+func (m *UserModel) CreateAPIToken(ctx context.Context, userID int32, name string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate token: %v", err)
+	}
+
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	id, err := m.q.InsertAPIToken(ctx, queries.InsertAPITokenParams{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashAPIToken(token),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to save API token for user %d: %v", userID, err)
+	}
+
+	m.logger.InfoContext(ctx, "Created API token.", "userID", userID, "tokenID", id, "name", name)
+
+	return token, nil
+}
+
+// GetByAPIToken returns the user that owns an API token and records that the token was used. If the
+// token is unknown, ErrInvalidAPIToken is returned.
+func (m *UserModel) GetByAPIToken(ctx context.Context, token string) (User, error) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return User{}, ErrInvalidAPIToken
+	}
+
+	row, err := m.q.GetUserByAPITokenHash(ctx, hashAPIToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, ErrInvalidAPIToken
+		}
+
+		return User{}, fmt.Errorf("failed to retrieve user by API token: %v", err)
+	}
+
+	return userFromModel(row.User), nil
+}
+
+func (m *UserModel) ListAPITokens(ctx context.Context, userID int32) ([]APIToken, error) {
+	rows, err := m.q.ListAPITokensByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens for user %d: %v", userID, err)
+	}
+
+	tokens := make([]APIToken, len(rows))
+	for i, row := range rows {
+		tokens[i] = APIToken{
+			ID:        row.ID,
+			Name:      row.Name,
+			CreatedAt: row.CreatedAt.Time,
+		}
+
+		if row.LastUsedAt.Valid {
+			tokens[i].LastUsedAt = &row.LastUsedAt.Time
+		}
+	}
+
+	return tokens, nil
+}
+
+func (m *UserModel) DeleteAPIToken(ctx context.Context, userID int32, tokenID int32) error {
+	deleted, err := m.q.DeleteAPIToken(ctx, queries.DeleteAPITokenParams{UserID: userID, ID: tokenID})
+	if err != nil {
+		return fmt.Errorf("failed to delete API token %d: %v", tokenID, err)
+	}
+
+	if deleted == 0 {
+		return newNotFoundError("API token", tokenID, pgx.ErrNoRows)
+	}
+
+	m.logger.InfoContext(ctx, "Deleted API token.", "userID", userID, "tokenID", tokenID)
+
+	return nil
+}
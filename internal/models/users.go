@@ -0,0 +1,185 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models/queries"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrDuplicateUsername is returned when trying to create a user whose username is taken.
+	ErrDuplicateUsername = errors.New("models: duplicate username")
+
+	// ErrInvalidCredentials is returned when a username and password do not match a user.
+	ErrInvalidCredentials = errors.New("models: invalid credentials")
+)
+
+const passwordHashCost = 12
+
+type User struct {
+	ID        int32
+	Username  string
+	CreatedAt time.Time
+
+	// IsAdmin indicates the user can edit ACS content.
+	IsAdmin bool
+
+	Role UserRole
+
+	// ClassRating is the class rating the user is training for. It is nil if they haven't chosen
+	// one, in which case every task applies to them.
+	ClassRating *ClassRating
+}
+
+// IsInstructor indicates the user can follow the progress of their assigned students.
+func (u User) IsInstructor() bool {
+	return u.Role == UserRoleInstructor
+}
+
+func userFromModel(m queries.User) User {
+	user := User{
+		ID:        m.ID,
+		Username:  m.Username,
+		CreatedAt: m.CreatedAt.Time,
+		IsAdmin:   m.IsAdmin,
+		Role:      UserRole(m.Role),
+	}
+
+	if m.ClassRating.Valid {
+		rating := ClassRating(m.ClassRating.ClassRating)
+		user.ClassRating = &rating
+	}
+
+	return user
+}
+
+type UserModel struct {
+	logger *slog.Logger
+	q      queries.Queries
+}
+
+func NewUserModel(logger *slog.Logger, db *pgxpool.Pool) *UserModel {
+	return &UserModel{logger, *queries.New(db)}
+}
+
+func (m *UserModel) Insert(ctx context.Context, username string, password string) (int32, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost)
+	if err != nil {
+		return 0, fmt.Errorf("failed to hash password: %v", err)
+	}
+
+	id, err := m.q.InsertUser(ctx, queries.InsertUserParams{
+		Username:     username,
+		PasswordHash: string(hash),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, ErrDuplicateUsername
+		}
+
+		return 0, fmt.Errorf("failed to insert user %s: %v", username, err)
+	}
+
+	m.logger.InfoContext(ctx, "Created user.", "userID", id, "username", username)
+
+	return id, nil
+}
+
+// Authenticate returns the ID of the user with the provided credentials. If the credentials do not
+// match a user, ErrInvalidCredentials is returned.
+func (m *UserModel) Authenticate(ctx context.Context, username string, password string) (int32, error) {
+	user, err := m.q.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrInvalidCredentials
+		}
+
+		return 0, fmt.Errorf("failed to retrieve user %s: %v", username, err)
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err != nil {
+		// Accounts migrated from before authentication existed have an empty hash. The comparison
+		// fails for those as well, which is what we want until the account is claimed.
+		return 0, ErrInvalidCredentials
+	}
+
+	return user.ID, nil
+}
+
+func (m *UserModel) GetByID(ctx context.Context, id int32) (User, error) {
+	user, err := m.q.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, newNotFoundError("user", id, err)
+		}
+
+		return User{}, fmt.Errorf("failed to retrieve user %d: %v", id, err)
+	}
+
+	return userFromModel(user), nil
+}
+
+func (m *UserModel) GetByUsername(ctx context.Context, username string) (User, error) {
+	user, err := m.q.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, newNotFoundError("user", username, err)
+		}
+
+		return User{}, fmt.Errorf("failed to retrieve user %s: %v", username, err)
+	}
+
+	return userFromModel(user), nil
+}
+
+func (m *UserModel) SetPassword(ctx context.Context, username string, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %v", err)
+	}
+
+	updated, err := m.q.SetUserPasswordHash(ctx, queries.SetUserPasswordHashParams{
+		Username:     username,
+		PasswordHash: string(hash),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update password for %s: %v", username, err)
+	}
+
+	if updated == 0 {
+		return fmt.Errorf("no user named %s", username)
+	}
+
+	m.logger.InfoContext(ctx, "Updated user password.", "username", username)
+
+	return nil
+}
+
+// SetAdmin grants or revokes a user's ability to edit ACS content.
+func (m *UserModel) SetAdmin(ctx context.Context, username string, admin bool) error {
+	updated, err := m.q.SetUserAdmin(ctx, queries.SetUserAdminParams{
+		Username: username,
+		IsAdmin:  admin,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update admin status for %s: %v", username, err)
+	}
+
+	if updated == 0 {
+		return fmt.Errorf("no user named %s", username)
+	}
+
+	m.logger.InfoContext(ctx, "Updated admin status.", "username", username, "admin", admin)
+
+	return nil
+}
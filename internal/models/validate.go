@@ -0,0 +1,340 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cdriehuys/flight-school/acs"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const acsSchemaURL = "https://github.com/cdriehuys/flight-school/acs/schema/acs.json"
+
+var acsSchema = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(acs.Schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ACS schema: %v", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(acsSchemaURL, doc); err != nil {
+		return nil, fmt.Errorf("failed to load ACS schema: %v", err)
+	}
+
+	return compiler.Compile(acsSchemaURL)
+})
+
+// ACSViolation describes a single problem with an ACS document.
+type ACSViolation struct {
+	// Path is a JSON pointer to the offending value, e.g. "/areas/0/tasks/2/objective".
+	Path string
+
+	// FullPublicID identifies the closest item containing the offending value, e.g. "PA.I.C". It
+	// is empty if the document does not have a usable ID.
+	FullPublicID string
+
+	Message string
+}
+
+func (v ACSViolation) String() string {
+	if v.FullPublicID == "" {
+		return fmt.Sprintf("%s: %s", v.Path, v.Message)
+	}
+
+	return fmt.Sprintf("%s (%s): %s", v.Path, v.FullPublicID, v.Message)
+}
+
+// ACSValidationError is returned when an ACS document is not valid.
+type ACSValidationError struct {
+	Violations []ACSViolation
+}
+
+func (e *ACSValidationError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = v.String()
+	}
+
+	return fmt.Sprintf("ACS document has %d problems:\n  %s", len(e.Violations), strings.Join(lines, "\n  "))
+}
+
+// DecodeACS reads an ACS document, validating it against the ACS schema and checking that public
+// IDs are unique and that tasks use the elements of their kind of document. If the document is
+// invalid, an *ACSValidationError listing every problem is returned.
+func DecodeACS(r io.Reader) (ExternalACS, error) {
+	schema, err := acsSchema()
+	if err != nil {
+		return ExternalACS{}, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ExternalACS{}, fmt.Errorf("failed to read ACS document: %v", err)
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return ExternalACS{}, fmt.Errorf("failed to parse ACS document: %v", err)
+	}
+
+	violations := make([]ACSViolation, 0)
+	if err := schema.Validate(instance); err != nil {
+		var validationErr *jsonschema.ValidationError
+		if !errors.As(err, &validationErr) {
+			return ExternalACS{}, fmt.Errorf("failed to validate ACS document: %v", err)
+		}
+
+		violations = schemaViolations(violations, validationErr, instance)
+	}
+
+	// Documents with the wrong types can't be decoded, but the schema has already reported why.
+	var doc ExternalACS
+	decodeErr := json.Unmarshal(data, &doc)
+	if decodeErr == nil {
+		violations = append(violations, duplicateIDViolations(doc)...)
+		violations = append(violations, elementKindViolations(doc)...)
+	}
+
+	if len(violations) > 0 {
+		return ExternalACS{}, &ACSValidationError{Violations: violations}
+	}
+
+	if decodeErr != nil {
+		return ExternalACS{}, fmt.Errorf("failed to decode ACS document: %v", decodeErr)
+	}
+
+	return doc, nil
+}
+
+// ValidateACS checks a document built in memory against the same rules as DecodeACS.
+func ValidateACS(doc ExternalACS) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode ACS document: %v", err)
+	}
+
+	_, err = DecodeACS(bytes.NewReader(data))
+
+	return err
+}
+
+var schemaMessages = message.NewPrinter(language.English)
+
+// schemaViolations flattens a schema validation error into the violations at its leaves.
+func schemaViolations(violations []ACSViolation, err *jsonschema.ValidationError, instance any) []ACSViolation {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			violations = schemaViolations(violations, cause, instance)
+		}
+
+		return violations
+	}
+
+	path := "/" + strings.Join(err.InstanceLocation, "/")
+
+	return append(violations, ACSViolation{
+		Path:         path,
+		FullPublicID: publicIDAt(instance, err.InstanceLocation),
+		Message:      err.ErrorKind.LocalizedString(schemaMessages),
+	})
+}
+
+// publicIDAt finds the full public ID of the deepest identifiable item along a path within a raw
+// ACS document.
+func publicIDAt(instance any, path []string) string {
+	root, ok := instance.(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	fullID, ok := root["id"].(string)
+	if !ok || fullID == "" {
+		return ""
+	}
+
+	current := root
+	for i := 0; i+1 < len(path); i += 2 {
+		list, ok := current[path[i]].([]any)
+		if !ok {
+			break
+		}
+
+		index, err := strconv.Atoi(path[i+1])
+		if err != nil || index < 0 || index >= len(list) {
+			break
+		}
+
+		item, ok := list[index].(map[string]any)
+		if !ok {
+			break
+		}
+
+		part := childPublicID(path[i], index, item)
+		if part == "" {
+			break
+		}
+
+		fullID += "." + part
+		current = item
+	}
+
+	return fullID
+}
+
+func childPublicID(collection string, index int, item map[string]any) string {
+	switch collection {
+	case "areas", "tasks":
+		id, _ := item["id"].(string)
+		return id
+
+	case "knowledge", "riskManagement", "skills", "elements":
+		id, ok := item["id"].(json.Number)
+		if !ok {
+			return ""
+		}
+
+		return string(elementTypeForCollection(collection)) + id.String()
+
+	case "subElements":
+		if index < len(subElementAlphabet) {
+			return string(subElementAlphabet[index])
+		}
+	}
+
+	return ""
+}
+
+func elementTypeForCollection(collection string) TaskElementType {
+	switch collection {
+	case "knowledge":
+		return TaskElementTypeKnowledge
+
+	case "riskManagement":
+		return TaskElementTypeRiskManagement
+
+	case "elements":
+		return TaskElementTypePTS
+	}
+
+	return TaskElementTypeSkills
+}
+
+// duplicateIDViolations finds public IDs that are reused within the same parent. The schema can't
+// express these constraints.
+func duplicateIDViolations(doc ExternalACS) []ACSViolation {
+	violations := make([]ACSViolation, 0)
+
+	areaIndexes := make(map[string]int)
+	for areaIndex, area := range doc.Areas {
+		areaPath := fmt.Sprintf("/areas/%d", areaIndex)
+		areaID := fmt.Sprintf("%s.%s", doc.ID, area.ID)
+
+		if first, ok := areaIndexes[area.ID]; ok {
+			violations = append(violations, ACSViolation{
+				Path:         areaPath + "/id",
+				FullPublicID: areaID,
+				Message:      fmt.Sprintf("duplicate area ID; first used by /areas/%d", first),
+			})
+		} else {
+			areaIndexes[area.ID] = areaIndex
+		}
+
+		taskIndexes := make(map[string]int)
+		for taskIndex, task := range area.Tasks {
+			taskPath := fmt.Sprintf("%s/tasks/%d", areaPath, taskIndex)
+			taskID := fmt.Sprintf("%s.%s", areaID, task.ID)
+
+			if first, ok := taskIndexes[task.ID]; ok {
+				violations = append(violations, ACSViolation{
+					Path:         taskPath + "/id",
+					FullPublicID: taskID,
+					Message:      fmt.Sprintf("duplicate task ID; first used by %s/tasks/%d", areaPath, first),
+				})
+			} else {
+				taskIndexes[task.ID] = taskIndex
+			}
+
+			elementGroups := []struct {
+				collection string
+				elements   []ExternalElement
+			}{
+				{"knowledge", task.Knowledge},
+				{"riskManagement", task.RiskManagement},
+				{"skills", task.Skills},
+				{"elements", task.PTSElements},
+			}
+
+			for _, group := range elementGroups {
+				elementIndexes := make(map[int32]int)
+				for elementIndex, element := range group.elements {
+					if first, ok := elementIndexes[element.ID]; ok {
+						violations = append(violations, ACSViolation{
+							Path: fmt.Sprintf("%s/%s/%d/id", taskPath, group.collection, elementIndex),
+							FullPublicID: fmt.Sprintf(
+								"%s.%s%d",
+								taskID,
+								elementTypeForCollection(group.collection),
+								element.ID,
+							),
+							Message: fmt.Sprintf(
+								"duplicate element ID; first used by %s/%s/%d",
+								taskPath,
+								group.collection,
+								first,
+							),
+						})
+					} else {
+						elementIndexes[element.ID] = elementIndex
+					}
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// elementKindViolations finds tasks whose elements don't match the kind of document. ACS tasks
+// split their elements into knowledge, risk management, and skills, while PTS tasks list them
+// together.
+func elementKindViolations(doc ExternalACS) []ACSViolation {
+	violations := make([]ACSViolation, 0)
+	kind := doc.DocumentKind()
+
+	for areaIndex, area := range doc.Areas {
+		for taskIndex, task := range area.Tasks {
+			collections := []struct {
+				name     string
+				elements []ExternalElement
+				kind     ACSKind
+			}{
+				{"knowledge", task.Knowledge, ACSKindACS},
+				{"riskManagement", task.RiskManagement, ACSKindACS},
+				{"skills", task.Skills, ACSKindACS},
+				{"elements", task.PTSElements, ACSKindPTS},
+			}
+
+			for _, collection := range collections {
+				if len(collection.elements) == 0 || collection.kind == kind {
+					continue
+				}
+
+				violations = append(violations, ACSViolation{
+					Path:         fmt.Sprintf("/areas/%d/tasks/%d/%s", areaIndex, taskIndex, collection.name),
+					FullPublicID: fmt.Sprintf("%s.%s.%s", doc.ID, area.ID, task.ID),
+					Message:      fmt.Sprintf("%s can only be used in %s documents", collection.name, strings.ToUpper(string(collection.kind))),
+				})
+			}
+		}
+	}
+
+	return violations
+}
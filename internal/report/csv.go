@@ -0,0 +1,30 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// WriteCSV writes one row per element, in document order.
+func WriteCSV(w io.Writer, r Report) error {
+	out := csv.NewWriter(w)
+
+	if err := out.Write([]string{"id", "type", "content", "confidence"}); err != nil {
+		return err
+	}
+
+	for _, area := range r.Areas {
+		for _, task := range area.Tasks {
+			for _, e := range Elements(task) {
+				row := []string{e.FullPublicID, TypeName(e.Type), e.Content, ConfidenceName(e.ConfidenceLevel)}
+				if err := out.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	out.Flush()
+
+	return out.Error()
+}
@@ -0,0 +1,75 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// markdownEscaper escapes characters that would otherwise be read as Markdown formatting.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`>`, `\>`,
+	`#`, `\#`,
+	`|`, `\|`,
+)
+
+// WriteMarkdown writes the report grouped by area and task. Areas, tasks, and elements are
+// ordered with the weakest first so the report doubles as a study plan.
+func WriteMarkdown(w io.Writer, r Report) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "# %s: %s\n\n", r.ACS.ID, markdownEscaper.Replace(r.ACS.Name))
+	fmt.Fprintf(
+		out,
+		"Confidence report for %s, generated %s. Overall confidence is %d%% (%d of %d votes).\n",
+		markdownEscaper.Replace(r.Username),
+		r.GeneratedAt.Local().Format(time.DateOnly),
+		Percent(r.ACS.Confidence),
+		r.ACS.Confidence.Votes,
+		r.ACS.Confidence.Possible,
+	)
+
+	for _, area := range r.WeakestFirst().Areas {
+		fmt.Fprintf(
+			out,
+			"\n## %s. %s (%d%%)\n",
+			area.PublicID,
+			markdownEscaper.Replace(area.Name),
+			Percent(area.Confidence),
+		)
+
+		for _, task := range area.Tasks {
+			fmt.Fprintf(
+				out,
+				"\n### %s: %s (%d%%)\n\n",
+				task.FullPublicID(),
+				markdownEscaper.Replace(task.Name),
+				Percent(task.Confidence),
+			)
+
+			for _, e := range WeakestElements(task) {
+				confidence := ConfidenceName(e.ConfidenceLevel)
+				if confidence == "" {
+					confidence = "unrated"
+				}
+
+				fmt.Fprintf(out, "- **%s** (%s): %s\n", e.FullPublicID, confidence, markdownEscaper.Replace(e.Content))
+
+				for _, s := range e.SubElements {
+					fmt.Fprintf(out, "    - %s. %s\n", s.PublicID(), markdownEscaper.Replace(s.Content))
+				}
+			}
+		}
+	}
+
+	return out.Flush()
+}
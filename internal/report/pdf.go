@@ -0,0 +1,283 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+	"github.com/go-pdf/fpdf"
+)
+
+// Page layout for the PDF study guide, in millimeters.
+const (
+	pdfMargin      = 15.0
+	pdfLineHeight  = 5.0
+	pdfCodeWidth   = 24.0
+	pdfIndicator   = 3.5
+	pdfIndent      = pdfIndicator + 2 + pdfCodeWidth
+	pdfSubIndent   = pdfIndent + 5
+	pdfFont        = "Helvetica"
+	pdfBodySize    = 10.0
+	pdfHeadingSize = 14.0
+)
+
+// pdfColor is an RGB color. The confidence colors match the web pages.
+type pdfColor struct{ r, g, b int }
+
+var (
+	pdfColorText   = pdfColor{0, 0, 0}
+	pdfColorSubtle = pdfColor{119, 119, 119}
+	pdfColorNote   = pdfColor{255, 247, 219}
+
+	pdfConfidenceColors = map[models.ConfidenceLevel]pdfColor{
+		models.ConfidenceLevelLow:    {158, 18, 5},
+		models.ConfidenceLevelMedium: {209, 170, 29},
+		models.ConfidenceLevelHigh:   {75, 214, 112},
+	}
+)
+
+// pdfWriter lays out a study guide. The core PDF fonts only cover Windows-1252, so all text passes
+// through tr first.
+type pdfWriter struct {
+	pdf *fpdf.Fpdf
+	tr  func(string) string
+}
+
+// WritePDF writes a printable study guide with every task in the report, in document order. Each
+// element is marked with the user's confidence and followed by their notes.
+func WritePDF(w io.Writer, r Report) error {
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.AliasNbPages("")
+
+	p := pdfWriter{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+
+	title := fmt.Sprintf("%s: %s", r.ACS.ID, r.ACS.Name)
+	if r.Area != nil {
+		title = fmt.Sprintf("%s: %s", r.Area.FullID(), r.Area.Name)
+	}
+
+	pdf.SetTitle(title, true)
+	pdf.SetAuthor(r.Username, true)
+	pdf.SetCreator("Flight School", true)
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin + 2)
+		p.setFont("", 8, pdfColorSubtle)
+		pdf.CellFormat(0, 4, p.tr(fmt.Sprintf("%s | %s | Page %d of {nb}", title, r.Username, pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+
+	pdf.AddPage()
+	p.writeTitle(r, title)
+
+	for _, area := range r.Areas {
+		p.writeArea(area, r.Area == nil)
+	}
+
+	return pdf.Output(w)
+}
+
+func (p pdfWriter) setFont(style string, size float64, color pdfColor) {
+	p.pdf.SetFont(pdfFont, style, size)
+	p.pdf.SetTextColor(color.r, color.g, color.b)
+}
+
+// ensureSpace starts a new page unless there is room for a block of the given height. This keeps
+// headings with their content and confidence markers on the same page as their element.
+func (p pdfWriter) ensureSpace(height float64) {
+	_, pageHeight := p.pdf.GetPageSize()
+	if p.pdf.GetY()+height > pageHeight-pdfMargin {
+		p.pdf.AddPage()
+	}
+}
+
+func (p pdfWriter) writeTitle(r Report, title string) {
+	confidence := r.ACS.Confidence
+	if r.Area != nil {
+		confidence = r.Area.Confidence
+	}
+
+	p.setFont("B", 20, pdfColorText)
+	p.pdf.MultiCell(0, 9, p.tr(title), "", "L", false)
+
+	p.setFont("", pdfBodySize, pdfColorSubtle)
+	p.pdf.MultiCell(
+		0,
+		pdfLineHeight,
+		p.tr(fmt.Sprintf(
+			"Study guide for %s, generated %s. Overall confidence is %d%%.",
+			r.Username,
+			r.GeneratedAt.Local().Format(time.DateOnly),
+			Percent(confidence),
+		)),
+		"",
+		"L",
+		false,
+	)
+	p.pdf.Ln(2)
+
+	// Legend for the confidence markers.
+	for _, level := range []*models.ConfidenceLevel{
+		ptr(models.ConfidenceLevelLow),
+		ptr(models.ConfidenceLevelMedium),
+		ptr(models.ConfidenceLevelHigh),
+		nil,
+	} {
+		p.writeIndicator(level)
+		p.pdf.SetX(p.pdf.GetX() + pdfIndicator + 1.5)
+
+		name := ConfidenceName(level)
+		if name == "" {
+			name = "unrated"
+		}
+
+		p.setFont("", 9, pdfColorText)
+		p.pdf.CellFormat(p.pdf.GetStringWidth(name)+6, pdfLineHeight, name, "", 0, "L", false, 0, "")
+	}
+
+	p.pdf.Ln(pdfLineHeight + 4)
+}
+
+func (p pdfWriter) writeArea(area Area, heading bool) {
+	if heading {
+		p.ensureSpace(30)
+		p.pdf.Bookmark(p.tr(area.FullID()+" "+area.Name), 0, -1)
+
+		p.setFont("B", 16, pdfColorText)
+		p.pdf.MultiCell(
+			0,
+			8,
+			p.tr(fmt.Sprintf("%s. %s (%d%%)", area.PublicID, area.Name, Percent(area.Confidence))),
+			"B",
+			"L",
+			false,
+		)
+		p.pdf.Ln(3)
+	}
+
+	for _, task := range area.Tasks {
+		p.writeTask(task)
+	}
+}
+
+func (p pdfWriter) writeTask(task models.Task) {
+	p.ensureSpace(25)
+	p.pdf.Bookmark(p.tr(task.FullPublicID()+" "+task.Name), 1, -1)
+
+	p.setFont("B", pdfHeadingSize, pdfColorText)
+	p.pdf.MultiCell(
+		0,
+		7,
+		p.tr(fmt.Sprintf("%s: %s (%d%%)", task.FullPublicID(), task.Name, Percent(task.Confidence))),
+		"",
+		"L",
+		false,
+	)
+
+	p.writeLabeled("Objective", task.Objective)
+	if task.Note != "" {
+		p.writeLabeled("Note", task.Note)
+	}
+
+	if len(task.References) > 0 {
+		p.writeLabeled("References", strings.Join(task.References, "; "))
+	}
+
+	p.writeElements("Knowledge", task.KnowledgeElements)
+	p.writeElements("Risk Management", task.RiskManagementElements)
+	p.writeElements("Skills", task.SkillElements)
+	p.writeElements("Elements", task.PTSElements)
+
+	p.pdf.Ln(4)
+}
+
+func (p pdfWriter) writeLabeled(label string, text string) {
+	p.pdf.Ln(1)
+	p.setFont("B", pdfBodySize, pdfColorText)
+	p.pdf.Write(pdfLineHeight, p.tr(label+": "))
+	p.setFont("", pdfBodySize, pdfColorText)
+	p.pdf.Write(pdfLineHeight, p.tr(text))
+	p.pdf.Ln(pdfLineHeight)
+}
+
+func (p pdfWriter) writeElements(heading string, elements []models.TaskElement) {
+	if len(elements) == 0 {
+		return
+	}
+
+	p.ensureSpace(2 * pdfLineHeight)
+	p.pdf.Ln(2)
+	p.setFont("B", 11, pdfColorText)
+	p.pdf.CellFormat(0, 6, heading, "", 1, "L", false, 0, "")
+
+	for _, e := range elements {
+		p.writeElement(e)
+	}
+}
+
+func (p pdfWriter) writeElement(e models.TaskElement) {
+	left, _, _, _ := p.pdf.GetMargins()
+
+	p.ensureSpace(pdfLineHeight)
+	p.pdf.Ln(0.5)
+
+	p.writeIndicator(e.ConfidenceLevel)
+
+	p.pdf.SetX(left + pdfIndicator + 2)
+	p.setFont("B", pdfBodySize, pdfColorText)
+	p.pdf.CellFormat(pdfCodeWidth, pdfLineHeight, p.tr(e.FullPublicID), "", 0, "L", false, 0, "")
+
+	p.setFont("", pdfBodySize, pdfColorText)
+	p.pdf.SetLeftMargin(left + pdfIndent)
+	p.pdf.MultiCell(0, pdfLineHeight, p.tr(e.Content), "", "L", false)
+
+	if e.Note != nil {
+		p.writeNote(e.Note)
+	}
+
+	p.pdf.SetLeftMargin(left + pdfSubIndent)
+	for _, s := range e.SubElements {
+		p.pdf.SetX(left + pdfSubIndent)
+		p.pdf.MultiCell(0, pdfLineHeight, p.tr(s.PublicID()+". "+s.Content), "", "L", false)
+
+		if s.Note != nil {
+			p.writeNote(s.Note)
+		}
+	}
+
+	p.pdf.SetLeftMargin(left)
+	p.pdf.SetX(left)
+}
+
+// writeNote writes a personal note as shaded text at the current indentation. Notes are written
+// in Markdown, which is printed as-is.
+func (p pdfWriter) writeNote(note *models.Note) {
+	p.pdf.SetFillColor(pdfColorNote.r, pdfColorNote.g, pdfColorNote.b)
+	p.setFont("I", 9, pdfColorText)
+	p.pdf.MultiCell(0, 4.5, p.tr(note.Content), "", "L", true)
+	p.pdf.Ln(0.5)
+}
+
+// writeIndicator draws a confidence marker at the current position without moving it. Unrated
+// elements get an empty box that can be filled in by hand.
+func (p pdfWriter) writeIndicator(level *models.ConfidenceLevel) {
+	x, y := p.pdf.GetX(), p.pdf.GetY()+(pdfLineHeight-pdfIndicator)/2
+
+	p.pdf.SetDrawColor(pdfColorSubtle.r, pdfColorSubtle.g, pdfColorSubtle.b)
+
+	if level == nil {
+		p.pdf.Rect(x, y, pdfIndicator, pdfIndicator, "D")
+		return
+	}
+
+	color := pdfConfidenceColors[*level]
+	p.pdf.SetFillColor(color.r, color.g, color.b)
+	p.pdf.Rect(x, y, pdfIndicator, pdfIndicator, "FD")
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,213 @@
+// Package report builds printable summaries of a user's confidence across an ACS.
+package report
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/cdriehuys/flight-school/internal/models"
+)
+
+// Source provides the ACS data a report is built from. It is satisfied by *models.ACSModel.
+type Source interface {
+	GetACS(ctx context.Context, userID int32, id string) (models.ACS, error)
+	ListAreasByACS(ctx context.Context, userID int32, acs string) ([]models.AreaOfOperation, error)
+	ListTasksByArea(ctx context.Context, userID int32, areaID int32) ([]models.TaskSummary, error)
+	GetTaskByArea(ctx context.Context, userID int32, acs string, areaID string, taskID string) (models.Task, error)
+}
+
+// Report is a user's confidence in every element of an ACS, in document order.
+type Report struct {
+	ACS         models.ACS
+	Username    string
+	GeneratedAt time.Time
+
+	// Area is set when the report only covers a single area of operation.
+	Area *models.AreaOfOperation
+
+	Areas []Area
+}
+
+type Area struct {
+	models.AreaOfOperation
+
+	Tasks []models.Task
+}
+
+// Build walks an ACS and collects the user's confidence in each of its elements.
+func Build(ctx context.Context, source Source, user models.User, acsID string) (Report, error) {
+	return build(ctx, source, user, acsID, "")
+}
+
+// BuildArea collects the user's confidence in each element of a single area of operation.
+func BuildArea(ctx context.Context, source Source, user models.User, acsID string, areaID string) (Report, error) {
+	return build(ctx, source, user, acsID, areaID)
+}
+
+// build walks an ACS, or only the area with the given public ID if it isn't empty.
+func build(ctx context.Context, source Source, user models.User, acsID string, areaID string) (Report, error) {
+	acs, err := source.GetACS(ctx, user.ID, acsID)
+	if err != nil {
+		return Report{}, err
+	}
+
+	areas, err := source.ListAreasByACS(ctx, user.ID, acs.ID)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to list areas for %s: %v", acs.ID, err)
+	}
+
+	report := Report{
+		ACS:         acs,
+		Username:    user.Username,
+		GeneratedAt: time.Now(),
+	}
+
+	if areaID != "" {
+		i := slices.IndexFunc(areas, func(a models.AreaOfOperation) bool { return a.PublicID == areaID })
+		if i == -1 {
+			return Report{}, &models.NotFoundError{Kind: "area", ID: acs.ID + "." + areaID}
+		}
+
+		areas = areas[i : i+1]
+		report.Area = &areas[0]
+	}
+
+	report.Areas = make([]Area, len(areas))
+	for i, area := range areas {
+		report.Areas[i] = Area{AreaOfOperation: area}
+
+		tasks, err := source.ListTasksByArea(ctx, user.ID, area.ID)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to list tasks for %s: %v", area.FullID(), err)
+		}
+
+		for _, summary := range tasks {
+			task, err := source.GetTaskByArea(ctx, user.ID, acs.ID, area.PublicID, summary.PublicID)
+			if err != nil {
+				return Report{}, fmt.Errorf("failed to get task %s: %v", summary.FullPublicID, err)
+			}
+
+			// The task detail doesn't include the task's overall confidence.
+			task.Confidence = summary.Confidence
+
+			report.Areas[i].Tasks = append(report.Areas[i].Tasks, task)
+		}
+	}
+
+	return report, nil
+}
+
+// Elements returns every element of a task, in document order.
+func Elements(task models.Task) []models.TaskElement {
+	elements := make([]models.TaskElement, 0, len(task.KnowledgeElements)+len(task.RiskManagementElements)+len(task.SkillElements)+len(task.PTSElements))
+	elements = append(elements, task.KnowledgeElements...)
+	elements = append(elements, task.RiskManagementElements...)
+	elements = append(elements, task.SkillElements...)
+	elements = append(elements, task.PTSElements...)
+
+	return elements
+}
+
+// TypeName describes the type of an element.
+func TypeName(t models.TaskElementType) string {
+	switch t {
+	case models.TaskElementTypeKnowledge:
+		return "Knowledge"
+	case models.TaskElementTypeRiskManagement:
+		return "Risk Management"
+	case models.TaskElementTypeSkills:
+		return "Skill"
+	case models.TaskElementTypePTS:
+		return "Element"
+	}
+
+	return string(t)
+}
+
+// ConfidenceName describes an element's confidence level, or returns an empty string if the
+// element hasn't been rated.
+func ConfidenceName(level *models.ConfidenceLevel) string {
+	if level == nil {
+		return ""
+	}
+
+	switch *level {
+	case models.ConfidenceLevelLow:
+		return "low"
+	case models.ConfidenceLevelMedium:
+		return "medium"
+	case models.ConfidenceLevelHigh:
+		return "high"
+	}
+
+	return ""
+}
+
+// Percent returns the share of possible votes that were cast, rounded to a whole percent. As on the
+// web pages, anything without elements counts as fully confident.
+func Percent(c models.Confidence) int {
+	if c.Possible == 0 {
+		return 100
+	}
+
+	return int(math.Round(float64(c.Votes) / float64(c.Possible) * 100))
+}
+
+// compareConfidence orders confidence totals from weakest to strongest.
+func compareConfidence(a, b models.Confidence) int {
+	// Compare a.Votes/a.Possible with b.Votes/b.Possible without dividing. Empty totals sort
+	// last since there's nothing to study.
+	if a.Possible == 0 || b.Possible == 0 {
+		return cmp.Compare(b.Possible, a.Possible)
+	}
+
+	return cmp.Compare(a.Votes*b.Possible, b.Votes*a.Possible)
+}
+
+// elementWeakness ranks elements for study. Low confidence comes first, followed by unrated
+// elements, matching the weighting used for study sessions.
+func elementWeakness(e models.TaskElement) int {
+	if e.ConfidenceLevel == nil {
+		return 1
+	}
+
+	switch *e.ConfidenceLevel {
+	case models.ConfidenceLevelLow:
+		return 0
+	case models.ConfidenceLevelMedium:
+		return 2
+	}
+
+	return 3
+}
+
+// WeakestFirst returns a copy of the report with areas and tasks ordered from least to most
+// confident. Ties keep their document order.
+func (r Report) WeakestFirst() Report {
+	sorted := r
+	sorted.Areas = slices.Clone(r.Areas)
+	slices.SortStableFunc(sorted.Areas, func(a, b Area) int { return compareConfidence(a.Confidence, b.Confidence) })
+
+	for i := range sorted.Areas {
+		tasks := slices.Clone(sorted.Areas[i].Tasks)
+		slices.SortStableFunc(tasks, func(a, b models.Task) int { return compareConfidence(a.Confidence, b.Confidence) })
+		sorted.Areas[i].Tasks = tasks
+	}
+
+	return sorted
+}
+
+// WeakestElements returns every element of a task ordered from least to most confident. Ties keep
+// their document order.
+func WeakestElements(task models.Task) []models.TaskElement {
+	elements := Elements(task)
+	slices.SortStableFunc(elements, func(a, b models.TaskElement) int {
+		return cmp.Compare(elementWeakness(a), elementWeakness(b))
+	})
+
+	return elements
+}
@@ -0,0 +1,52 @@
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    username TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE users
+    ADD CONSTRAINT ck_username_len CHECK (char_length(username) BETWEEN 1 AND 50);
+
+-- Session storage used by github.com/alexedwards/scs/pgxstore.
+CREATE TABLE sessions (
+    token TEXT PRIMARY KEY,
+    data BYTEA NOT NULL,
+    expiry TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX sessions_expiry_idx ON sessions (expiry);
+
+-- Votes cast before accounts existed are assigned to an initial account. The
+-- empty password hash can never be matched, so the account has to be claimed
+-- with `flight-school set-password initial` before it can be used.
+INSERT INTO users (username, password_hash)
+SELECT 'initial', ''
+WHERE EXISTS (SELECT 1 FROM element_confidence);
+
+ALTER TABLE element_confidence
+    ADD COLUMN user_id INTEGER REFERENCES users(id)
+        ON DELETE CASCADE;
+
+UPDATE element_confidence
+SET user_id = (SELECT id FROM users WHERE username = 'initial');
+
+ALTER TABLE element_confidence
+    ALTER COLUMN user_id SET NOT NULL,
+    DROP CONSTRAINT element_confidence_element_id_key,
+    ADD CONSTRAINT element_confidence_user_id_element_id_key UNIQUE (user_id, element_id);
+
+---- create above / drop below ----
+
+-- Only one set of votes can survive without accounts. Keep the oldest
+-- account's votes since that is the one the original votes were assigned to.
+DELETE FROM element_confidence
+WHERE user_id <> (SELECT id FROM users ORDER BY id LIMIT 1);
+
+ALTER TABLE element_confidence
+    DROP CONSTRAINT element_confidence_user_id_element_id_key,
+    ADD CONSTRAINT element_confidence_element_id_key UNIQUE (element_id),
+    DROP COLUMN user_id;
+
+DROP TABLE sessions;
+DROP TABLE users;
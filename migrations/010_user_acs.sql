@@ -0,0 +1,12 @@
+-- The ACS documents each user is currently studying for.
+CREATE TABLE user_acs (
+    user_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    acs_id VARCHAR(2) NOT NULL REFERENCES acs(id)
+        ON DELETE CASCADE,
+    PRIMARY KEY (user_id, acs_id)
+);
+
+---- create above / drop below ----
+
+DROP TABLE user_acs;
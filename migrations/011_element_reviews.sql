@@ -0,0 +1,26 @@
+-- Spaced repetition schedule for each element a user has rated. Scheduling
+-- follows the SM-2 algorithm, using the confidence vote as the recall quality.
+CREATE TABLE element_reviews (
+    user_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    element_id INTEGER NOT NULL REFERENCES acs_elements(id)
+        ON DELETE CASCADE,
+    repetitions INTEGER NOT NULL,
+    interval_days INTEGER NOT NULL,
+    ease_factor REAL NOT NULL,
+    -- Votes cast before reviews were tracked have no known rating time.
+    rated_at TIMESTAMPTZ,
+    due_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, element_id)
+);
+
+CREATE INDEX element_reviews_user_id_due_at_idx ON element_reviews (user_id, due_at);
+
+-- Existing votes are due immediately so they get a fresh rating.
+INSERT INTO element_reviews (user_id, element_id, repetitions, interval_days, ease_factor, due_at)
+SELECT user_id, element_id, 0, 0, 2.5, now()
+FROM element_confidence;
+
+---- create above / drop below ----
+
+DROP TABLE element_reviews;
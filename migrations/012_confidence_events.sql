@@ -0,0 +1,58 @@
+-- Every confidence vote is recorded as an immutable event so progress can be
+-- tracked over time. A NULL vote records that the user cleared their vote.
+CREATE TABLE confidence_events (
+    id INTEGER PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    element_id INTEGER NOT NULL REFERENCES acs_elements(id)
+        ON DELETE CASCADE,
+    vote SMALLINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX confidence_events_user_id_element_id_created_at_idx
+    ON confidence_events (user_id, element_id, created_at DESC, id DESC);
+
+-- Existing votes become the first event for each element. The time the vote was
+-- cast is only known if it has been rated since reviews were introduced.
+INSERT INTO confidence_events (user_id, element_id, vote, created_at)
+SELECT c.user_id, c.element_id, c.vote, COALESCE(r.rated_at, now())
+FROM element_confidence c
+    LEFT JOIN element_reviews r ON c.user_id = r.user_id AND c.element_id = r.element_id;
+
+DROP TABLE element_confidence;
+
+-- The current vote is the most recent event, as long as it wasn't cleared.
+CREATE VIEW element_confidence AS
+SELECT latest.user_id, latest.element_id, latest.vote, latest.created_at AS voted_at
+FROM (
+    SELECT DISTINCT ON (user_id, element_id) user_id, element_id, vote, created_at
+    FROM confidence_events
+    ORDER BY user_id, element_id, created_at DESC, id DESC
+) latest
+WHERE latest.vote IS NOT NULL;
+
+---- create above / drop below ----
+
+CREATE TABLE element_confidence_current AS
+SELECT user_id, element_id, vote
+FROM element_confidence;
+
+DROP VIEW element_confidence;
+
+CREATE TABLE element_confidence (
+    id INTEGER PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    element_id INTEGER NOT NULL REFERENCES acs_elements(id)
+        ON DELETE CASCADE,
+    vote SMALLINT NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    CONSTRAINT element_confidence_user_id_element_id_key UNIQUE (user_id, element_id)
+);
+
+INSERT INTO element_confidence (user_id, element_id, vote)
+SELECT user_id, element_id, vote
+FROM element_confidence_current;
+
+DROP TABLE element_confidence_current;
+DROP TABLE confidence_events;
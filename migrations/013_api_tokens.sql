@@ -0,0 +1,18 @@
+-- Bearer tokens for the JSON API. Only a hash of each token is stored, so a
+-- token can't be recovered after it is created.
+CREATE TABLE api_tokens (
+    id INTEGER PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    "name" TEXT NOT NULL,
+    token_hash BYTEA UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ
+);
+
+ALTER TABLE api_tokens
+    ADD CONSTRAINT ck_name_len CHECK (char_length("name") BETWEEN 1 AND 100);
+
+---- create above / drop below ----
+
+DROP TABLE api_tokens;
@@ -0,0 +1,14 @@
+-- Elements a user missed on the FAA knowledge test, as listed by the ACS codes
+-- on their Airman Knowledge Test Report.
+CREATE TABLE missed_elements (
+    user_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    element_id INTEGER NOT NULL REFERENCES acs_elements(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, element_id)
+);
+
+---- create above / drop below ----
+
+DROP TABLE missed_elements;
@@ -0,0 +1,35 @@
+-- A study session walks a user through a sample of elements from an ACS or one
+-- of its areas, one element at a time.
+CREATE TABLE study_sessions (
+    id INTEGER PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    acs_id VARCHAR(2) NOT NULL REFERENCES acs(id)
+        ON DELETE CASCADE,
+    -- Sessions covering an entire ACS have no area.
+    area_id INTEGER REFERENCES acs_areas(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ
+);
+
+-- The elements picked for a session, in the order they are presented. The
+-- user's vote is captured when the session starts so the summary can show how
+-- it changed.
+CREATE TABLE study_session_elements (
+    session_id INTEGER NOT NULL REFERENCES study_sessions(id)
+        ON DELETE CASCADE,
+    element_id INTEGER NOT NULL REFERENCES acs_elements(id)
+        ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    before_vote SMALLINT,
+    after_vote SMALLINT,
+    rated_at TIMESTAMPTZ,
+    PRIMARY KEY (session_id, element_id),
+    UNIQUE (session_id, position)
+);
+
+---- create above / drop below ----
+
+DROP TABLE study_session_elements;
+DROP TABLE study_sessions;
@@ -0,0 +1,52 @@
+-- Mock oral exams. An examiner generates a plan of tasks from an ACS for a
+-- student, then grades how the student did on each element of those tasks.
+CREATE TYPE exam_grade AS ENUM ('pass', 'discuss', 'fail');
+
+CREATE TABLE exams (
+    id INTEGER PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    acs_id VARCHAR(2) NOT NULL REFERENCES acs(id)
+        ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    examiner_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    -- The seed used to pick tasks, so a plan can be generated again.
+    seed BIGINT NOT NULL,
+    scenario TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE exams
+    ADD CONSTRAINT ck_scenario_len CHECK (char_length(scenario) <= 2000);
+
+CREATE INDEX exams_student_id_idx ON exams (student_id);
+CREATE INDEX exams_examiner_id_idx ON exams (examiner_id);
+
+-- The tasks picked for an exam. Tasks are stored rather than regenerated from
+-- the seed because the weighting depends on the student's confidence, which
+-- changes over time.
+CREATE TABLE exam_tasks (
+    exam_id INTEGER NOT NULL REFERENCES exams(id)
+        ON DELETE CASCADE,
+    task_id INTEGER NOT NULL REFERENCES acs_area_tasks(id)
+        ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (exam_id, task_id)
+);
+
+CREATE TABLE exam_element_grades (
+    exam_id INTEGER NOT NULL REFERENCES exams(id)
+        ON DELETE CASCADE,
+    element_id INTEGER NOT NULL REFERENCES acs_elements(id)
+        ON DELETE CASCADE,
+    grade exam_grade NOT NULL,
+    graded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (exam_id, element_id)
+);
+
+---- create above / drop below ----
+
+DROP TABLE exam_element_grades;
+DROP TABLE exam_tasks;
+DROP TABLE exams;
+DROP TYPE exam_grade;
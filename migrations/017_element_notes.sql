@@ -0,0 +1,36 @@
+-- Personal markdown notes on elements. Notes belong to the element rather than
+-- a particular version of the document, so they are kept when an ACS is
+-- repopulated.
+CREATE TABLE element_notes (
+    user_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    element_id INTEGER NOT NULL REFERENCES acs_elements(id)
+        ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, element_id)
+);
+
+ALTER TABLE element_notes
+    ADD CONSTRAINT ck_content_len CHECK (char_length(content) BETWEEN 1 AND 10000);
+
+-- Sub-element rows are matched by their position when an ACS is repopulated,
+-- so notes on them are keyed the same way instead of referencing the row.
+CREATE TABLE sub_element_notes (
+    user_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    element_id INTEGER NOT NULL REFERENCES acs_elements(id)
+        ON DELETE CASCADE,
+    sub_element_order INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, element_id, sub_element_order)
+);
+
+ALTER TABLE sub_element_notes
+    ADD CONSTRAINT ck_content_len CHECK (char_length(content) BETWEEN 1 AND 10000);
+
+---- create above / drop below ----
+
+DROP TABLE sub_element_notes;
+DROP TABLE element_notes;
@@ -0,0 +1,28 @@
+-- Full-text search indexes. The search queries must use the same expressions
+-- for Postgres to pick these up.
+CREATE INDEX acs_area_tasks_search_idx ON acs_area_tasks
+    USING GIN (to_tsvector('english', "name" || ' ' || objective || ' ' || note));
+
+CREATE INDEX task_references_search_idx ON task_references
+    USING GIN (to_tsvector('english', document));
+
+CREATE INDEX acs_elements_search_idx ON acs_elements
+    USING GIN (to_tsvector('english', content));
+
+CREATE INDEX acs_subelements_search_idx ON acs_subelements
+    USING GIN (to_tsvector('english', content));
+
+CREATE INDEX element_notes_search_idx ON element_notes
+    USING GIN (to_tsvector('english', content));
+
+CREATE INDEX sub_element_notes_search_idx ON sub_element_notes
+    USING GIN (to_tsvector('english', content));
+
+---- create above / drop below ----
+
+DROP INDEX sub_element_notes_search_idx;
+DROP INDEX element_notes_search_idx;
+DROP INDEX acs_subelements_search_idx;
+DROP INDEX acs_elements_search_idx;
+DROP INDEX task_references_search_idx;
+DROP INDEX acs_area_tasks_search_idx;
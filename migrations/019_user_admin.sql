@@ -0,0 +1,8 @@
+-- Admins can edit ACS content from the web interface.
+ALTER TABLE users
+    ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT false;
+
+---- create above / drop below ----
+
+ALTER TABLE users
+    DROP COLUMN is_admin;
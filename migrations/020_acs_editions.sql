@@ -0,0 +1,40 @@
+-- Each edition of an ACS is stored separately so that editions can be loaded
+-- side by side. An edition's ID combines its code and edition, e.g. PA-6C.
+-- Documents loaded before editions existed keep their code as their ID.
+ALTER TABLE acs ALTER COLUMN id TYPE VARCHAR(16);
+ALTER TABLE acs_areas ALTER COLUMN acs_id TYPE VARCHAR(16);
+ALTER TABLE user_acs ALTER COLUMN acs_id TYPE VARCHAR(16);
+ALTER TABLE study_sessions ALTER COLUMN acs_id TYPE VARCHAR(16);
+ALTER TABLE exams ALTER COLUMN acs_id TYPE VARCHAR(16);
+
+ALTER TABLE acs
+    ADD COLUMN code VARCHAR(2),
+    ADD COLUMN edition VARCHAR(8) NOT NULL DEFAULT '',
+    -- The current edition is the one new users should study.
+    ADD COLUMN is_current BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE acs SET code = id, is_current = true;
+
+ALTER TABLE acs
+    ALTER COLUMN code SET NOT NULL,
+    ADD CONSTRAINT acs_code_edition_key UNIQUE (code, edition);
+
+CREATE UNIQUE INDEX acs_current_edition_idx ON acs (code) WHERE is_current;
+
+---- create above / drop below ----
+
+DELETE FROM acs WHERE id <> code;
+
+DROP INDEX acs_current_edition_idx;
+
+ALTER TABLE acs
+    DROP CONSTRAINT acs_code_edition_key,
+    DROP COLUMN is_current,
+    DROP COLUMN edition,
+    DROP COLUMN code;
+
+ALTER TABLE exams ALTER COLUMN acs_id TYPE VARCHAR(2);
+ALTER TABLE study_sessions ALTER COLUMN acs_id TYPE VARCHAR(2);
+ALTER TABLE user_acs ALTER COLUMN acs_id TYPE VARCHAR(2);
+ALTER TABLE acs_areas ALTER COLUMN acs_id TYPE VARCHAR(2);
+ALTER TABLE acs ALTER COLUMN id TYPE VARCHAR(2);
@@ -0,0 +1,26 @@
+-- Instructors can follow the progress of the students assigned to them.
+CREATE TYPE user_role AS ENUM ('student', 'instructor');
+
+ALTER TABLE users
+    ADD COLUMN "role" user_role NOT NULL DEFAULT 'student';
+
+CREATE TABLE instructor_students (
+    instructor_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (instructor_id, student_id),
+    CHECK (instructor_id <> student_id)
+);
+
+CREATE INDEX instructor_students_student_id_idx ON instructor_students (student_id);
+
+---- create above / drop below ----
+
+DROP TABLE instructor_students;
+
+ALTER TABLE users
+    DROP COLUMN "role";
+
+DROP TYPE user_role;
@@ -0,0 +1,16 @@
+-- Instructors sign off the elements a student has demonstrated satisfactorily.
+-- Sign-offs are kept apart from the student's own confidence ratings.
+CREATE TABLE element_signoffs (
+    student_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    element_id INTEGER NOT NULL REFERENCES acs_elements(id)
+        ON DELETE CASCADE,
+    instructor_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    signed_off_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (student_id, element_id)
+);
+
+---- create above / drop below ----
+
+DROP TABLE element_signoffs;
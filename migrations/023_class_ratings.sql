@@ -0,0 +1,37 @@
+-- Some tasks only apply to certain aircraft classes, such as the seaplane tasks
+-- in the Private Pilot ACS. Tasks without any classes apply to every class. The
+-- classes are stored as text because pgx can't decode arrays of an enum without
+-- registering the type on every connection.
+ALTER TABLE acs_area_tasks
+    ADD COLUMN applies_to VARCHAR(4)[] NOT NULL DEFAULT '{}',
+    ADD CONSTRAINT acs_area_tasks_applies_to_check
+        CHECK (applies_to <@ ARRAY['ASEL', 'AMEL', 'ASES', 'AMES']::VARCHAR(4)[]);
+
+CREATE TYPE class_rating AS ENUM ('ASEL', 'AMEL', 'ASES', 'AMES');
+
+-- Users who haven't chosen a class rating see every task.
+ALTER TABLE users
+    ADD COLUMN class_rating class_rating;
+
+CREATE FUNCTION task_applies_to_user(applies_to VARCHAR(4)[], user_id INTEGER)
+RETURNS BOOLEAN
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT cardinality(applies_to) = 0 OR COALESCE(
+        (SELECT u.class_rating::text = ANY(applies_to) FROM users u WHERE u.id = user_id),
+        true
+    );
+$$;
+
+---- create above / drop below ----
+
+DROP FUNCTION task_applies_to_user;
+
+ALTER TABLE users
+    DROP COLUMN class_rating;
+
+DROP TYPE class_rating;
+
+ALTER TABLE acs_area_tasks
+    DROP COLUMN applies_to;
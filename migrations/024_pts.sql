@@ -0,0 +1,28 @@
+-- Practical Test Standards predate the ACS. Their tasks list enumerated
+-- elements without splitting them into knowledge, risk management, and skills,
+-- so those elements get a type of their own.
+CREATE TYPE acs_kind AS ENUM ('acs', 'pts');
+
+ALTER TABLE acs
+    ADD COLUMN kind acs_kind NOT NULL DEFAULT 'acs';
+
+ALTER TYPE acs_element_type ADD VALUE 'E';
+
+---- create above / drop below ----
+
+DELETE FROM acs_elements
+WHERE "type" = 'E';
+
+ALTER TYPE acs_element_type RENAME TO acs_element_type_old;
+
+CREATE TYPE acs_element_type AS ENUM ('K', 'R', 'S');
+
+ALTER TABLE acs_elements
+    ALTER COLUMN "type" TYPE acs_element_type USING "type"::text::acs_element_type;
+
+DROP TYPE acs_element_type_old;
+
+ALTER TABLE acs
+    DROP COLUMN kind;
+
+DROP TYPE acs_kind;
@@ -0,0 +1,75 @@
+-- A bank of multiple choice knowledge test questions. Questions are tagged with
+-- the codes of the elements they test, e.g. PA.I.B.K3. Codes are shared by
+-- every edition of an ACS, so tags are kept as text rather than referencing
+-- element rows.
+CREATE TABLE questions (
+    id INTEGER PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    -- The question's ID in the imported file, so importing a file again
+    -- updates its questions instead of duplicating them.
+    public_id VARCHAR(50) NOT NULL UNIQUE,
+    prompt TEXT NOT NULL,
+    explanation TEXT NOT NULL DEFAULT '',
+    -- The position of the correct choice.
+    answer INTEGER NOT NULL
+);
+
+ALTER TABLE questions
+    ADD CONSTRAINT ck_prompt_len CHECK (char_length(prompt) BETWEEN 1 AND 2000),
+    ADD CONSTRAINT ck_explanation_len CHECK (char_length(explanation) <= 5000);
+
+CREATE TABLE question_choices (
+    question_id INTEGER NOT NULL REFERENCES questions(id)
+        ON DELETE CASCADE,
+    "order" INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    PRIMARY KEY (question_id, "order")
+);
+
+CREATE TABLE question_elements (
+    question_id INTEGER NOT NULL REFERENCES questions(id)
+        ON DELETE CASCADE,
+    element_code VARCHAR(32) NOT NULL,
+    PRIMARY KEY (question_id, element_code)
+);
+
+CREATE INDEX question_elements_element_code_idx ON question_elements (element_code);
+
+-- A practice test is a sample of questions about an ACS or one of its areas.
+CREATE TABLE practice_tests (
+    id INTEGER PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id INTEGER NOT NULL REFERENCES users(id)
+        ON DELETE CASCADE,
+    acs_id VARCHAR(16) NOT NULL REFERENCES acs(id)
+        ON DELETE CASCADE,
+    -- Tests covering an entire ACS have no area.
+    area_id INTEGER REFERENCES acs_areas(id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX practice_tests_user_id_idx ON practice_tests (user_id);
+
+-- The questions on a practice test, in the order they are asked. Whether an
+-- answer was correct is recorded when it is given so that results don't change
+-- if the question's choices are edited by a later import.
+CREATE TABLE practice_test_questions (
+    test_id INTEGER NOT NULL REFERENCES practice_tests(id)
+        ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES questions(id)
+        ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    choice INTEGER,
+    correct BOOLEAN,
+    answered_at TIMESTAMPTZ,
+    PRIMARY KEY (test_id, question_id),
+    UNIQUE (test_id, position)
+);
+
+---- create above / drop below ----
+
+DROP TABLE practice_test_questions;
+DROP TABLE practice_tests;
+DROP TABLE question_elements;
+DROP TABLE question_choices;
+DROP TABLE questions;
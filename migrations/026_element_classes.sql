@@ -0,0 +1,20 @@
+-- Elements and sub-elements can also be limited to certain aircraft classes,
+-- such as the water rudder elements of a takeoff task that applies to every
+-- class. They are stored the same way as the classes of a task.
+ALTER TABLE acs_elements
+    ADD COLUMN applies_to VARCHAR(4)[] NOT NULL DEFAULT '{}',
+    ADD CONSTRAINT acs_elements_applies_to_check
+        CHECK (applies_to <@ ARRAY['ASEL', 'AMEL', 'ASES', 'AMES']::VARCHAR(4)[]);
+
+ALTER TABLE acs_subelements
+    ADD COLUMN applies_to VARCHAR(4)[] NOT NULL DEFAULT '{}',
+    ADD CONSTRAINT acs_subelements_applies_to_check
+        CHECK (applies_to <@ ARRAY['ASEL', 'AMEL', 'ASES', 'AMES']::VARCHAR(4)[]);
+
+---- create above / drop below ----
+
+ALTER TABLE acs_subelements
+    DROP COLUMN applies_to;
+
+ALTER TABLE acs_elements
+    DROP COLUMN applies_to;